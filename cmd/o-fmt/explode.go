@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// explodeIndexName is the name, without extension, of the index file written
+// next to the exploded documents.
+const explodeIndexName = "index"
+
+// explodeFileData is the data of the explode filename template. Every field is
+// a single file name component (see utils.SafeFileName).
+type explodeFileData struct {
+	Name        string
+	Path        string
+	Webhook     string
+	Method      string
+	Tag         string
+	OperationID string
+	Ext         string
+}
+
+// explodeIndex is the content of the index file.
+type explodeIndex struct {
+	By        string              `yaml:"by" json:"by"`
+	Documents []explodeIndexEntry `yaml:"documents" json:"documents"`
+}
+
+type explodeIndexEntry struct {
+	File        string `yaml:"file" json:"file"`
+	Path        string `yaml:"path,omitempty" json:"path,omitempty"`
+	Webhook     string `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+	Method      string `yaml:"method,omitempty" json:"method,omitempty"`
+	Tag         string `yaml:"tag,omitempty" json:"tag,omitempty"`
+	OperationID string `yaml:"operationId,omitempty" json:"operationId,omitempty"`
+}
+
+// writeExploded writes one document per path, tag or operation of doc into the
+// directory dir, named after the filename template, and an index file listing
+// them.
+func writeExploded(doc *openapi3.T, by, fileTemplate, dir, format, version string) error {
+	if fileTemplate == "" {
+		fileTemplate = "{{.Name}}.{{.Ext}}"
+	}
+	tmpl, err := template.New("file").Option("missingkey=error").Parse(fileTemplate)
+	if err != nil {
+		return fmt.Errorf("Error parsing explode template '%s': %w", fileTemplate, err)
+	}
+
+	exploded, err := utils.Explode(doc, by)
+	if err != nil {
+		return fmt.Errorf("Error exploding OpenAPI document by %s: %w", by, err)
+	}
+
+	index := explodeIndex{By: by}
+	written := make(map[string]bool)
+	for _, part := range exploded {
+		file, err := explodeFileName(tmpl, part, format)
+		if err != nil {
+			return err
+		}
+		if file == explodeIndexName+"."+format {
+			return fmt.Errorf("Error: explode template renders the name of the index file '%s'", file)
+		}
+		if written[file] {
+			return fmt.Errorf("Error: explode template renders '%s' for more than one document", file)
+		}
+		written[file] = true
+
+		data, err := marshalDocument(part.Doc, format, version)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("Error creating output directory '%s': %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("Error writing output file '%s': %w", target, err)
+		}
+		index.Documents = append(index.Documents, explodeIndexEntry{
+			File:        file,
+			Path:        part.Path,
+			Webhook:     part.Webhook,
+			Method:      part.Method,
+			Tag:         part.Tag,
+			OperationID: part.OperationID,
+		})
+	}
+
+	data, err := encode(index, format)
+	if err != nil {
+		return fmt.Errorf("Error marshalling explode index: %w", err)
+	}
+	indexPath := filepath.Join(dir, explodeIndexName+"."+format)
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return fmt.Errorf("Error writing output file '%s': %w", indexPath, err)
+	}
+	return nil
+}
+
+// explodeFileName renders the filename template for an exploded document. The
+// result is a slash-separated path relative to the output directory.
+func explodeFileName(tmpl *template.Template, part utils.ExplodedDocument, format string) (string, error) {
+	fileData := explodeFileData{
+		Name:        part.Name(),
+		Path:        utils.SafeFileName(part.Path),
+		Webhook:     utils.SafeFileName(part.Webhook),
+		Method:      utils.SafeFileName(strings.ToLower(part.Method)),
+		Tag:         utils.SafeFileName(part.Tag),
+		OperationID: utils.SafeFileName(part.OperationID),
+		Ext:         format,
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, fileData); err != nil {
+		return "", fmt.Errorf("Error rendering explode template: %w", err)
+	}
+	file := filepath.ToSlash(filepath.Clean(filepath.FromSlash(b.String())))
+	if file == "." || filepath.IsAbs(b.String()) || file == ".." || strings.HasPrefix(file, "../") {
+		return "", fmt.Errorf("Error: explode template renders '%s', which is not a file inside the output directory", b.String())
+	}
+	return file, nil
+}
+
+// encode encodes a value in the given format ("yaml" or "json").
+func encode(v any, format string) ([]byte, error) {
+	if format == "yaml" {
+		return yaml.Marshal(v)
+	}
+	return json.Marshal(v)
+}
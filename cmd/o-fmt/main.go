@@ -1,12 +1,18 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/0x726f6f6b6965/openapi-fmt/config"
 	"github.com/0x726f6f6b6965/openapi-fmt/utils"
 	"github.com/getkin/kin-openapi/openapi3"
+	oasyaml "github.com/oasdiff/yaml"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -26,16 +32,49 @@ const (
 	ConfigShortFlag       = "c"
 	RmExtsFlag            = "remove-exts"
 	RmExtsShortFlag       = "r"
+	OutputVersionFlag     = "output-version"
+	InputFormatFlag       = "input-format"
+	TagsFlag              = "tags"
+	TagsShortFlag         = "t"
+	OperationsFlag        = "operations"
+	ExcludePathsFlag      = "exclude-paths"
+	ExplodeFlag           = "explode"
+	ExplodeTemplateFlag   = "explode-template"
+	KeepOpsFlag           = "keep-ops"
+	DropOpsFlag           = "drop-ops"
+	DropDeprecatedFlag    = "drop-deprecated"
+	DropExtsFlag          = "drop-exts"
+	RenameExtsFlag        = "rename-exts"
+	// StdioPath used as input or output path reads from stdin or writes to stdout.
+	StdioPath = "-"
 )
 
 var (
-	configFile    string
-	inputPath     string
-	outputPath    string
-	outputFmt     string
-	excludesSlice []string
-	pathsSlice    []string
-	rmEnable      bool
+	configFile        string
+	inputPath         string
+	outputPath        string
+	outputFmt         string
+	excludesSlice     []string
+	dropExtsSlice     []string
+	renameExtsSlice   []string
+	pathsSlice        []string
+	tagsSlice         []string
+	operationsSlice   []string
+	excludePathsSlice []string
+	keepOpsSlice      []string
+	dropOpsSlice      []string
+	dropDeprecated    bool
+	explodeBy         string
+	explodeTemplate   string
+	rmEnable          bool
+	outputVersion     string
+	inputFmt          string
+
+	// stdin and stdout are used when the input or output path is StdioPath.
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
+	// stderr receives the warnings.
+	stderr io.Writer = os.Stderr
 )
 
 func main() {
@@ -43,14 +82,29 @@ func main() {
 		Use:   "o-fmt",
 		Short: "This is a command-line tool for formatting OpenAPI documents.",
 		RunE:  RunE,
+		// Errors are reported on stderr by main only, so they never end up in a piped document.
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 	rootCmd.PersistentFlags().StringVarP(&configFile, ConfigFlag, ConfigShortFlag, "", "path to the config file (e.g. config.yaml)")
-	rootCmd.PersistentFlags().StringVarP(&inputPath, InputFileFlag, InputFileShortFlag, "", "path to the input OpenAPI file")
-	rootCmd.PersistentFlags().StringVarP(&outputPath, OutputFileFlag, OutputFileShortFlag, "", "path to the output OpenAPI file")
-	rootCmd.PersistentFlags().StringVarP(&outputFmt, OutputFormatFlag, OutputFormatShortFlag, "yaml", "format of the output file (yaml or json)")
-	rootCmd.PersistentFlags().StringSliceVarP(&excludesSlice, ExcludesFlag, ExcludesShortFlag, []string{}, "extensions to exclude from the output file")
+	rootCmd.PersistentFlags().StringVarP(&inputPath, InputFileFlag, InputFileShortFlag, "", "path to the input OpenAPI file (- for stdin)")
+	rootCmd.PersistentFlags().StringVarP(&outputPath, OutputFileFlag, OutputFileShortFlag, "", "path to the output OpenAPI file (- or empty for stdout)")
+	rootCmd.PersistentFlags().StringVarP(&outputFmt, OutputFormatFlag, OutputFormatShortFlag, "", "format of the output file (yaml or json, default yaml or the detected input format when reading from stdin)")
+	rootCmd.PersistentFlags().StringSliceVarP(&excludesSlice, ExcludesFlag, ExcludesShortFlag, []string{}, "extensions to exclude from the output file (names, globs such as x-amazon-apigateway-* or regular expressions prefixed with re:)")
+	rootCmd.PersistentFlags().StringSliceVar(&dropExtsSlice, DropExtsFlag, []string{}, "extensions to remove from the output file, keeping all the others (names, globs such as x-codegen-* or regular expressions prefixed with re:)")
+	rootCmd.PersistentFlags().StringSliceVar(&renameExtsSlice, RenameExtsFlag, []string{}, "extensions to rename as from=to pairs, or to promote into a standard field (e.g. x-go-name=x-oapi-codegen-extra-tags or x-nullable=nullable)")
 	rootCmd.PersistentFlags().StringSliceVarP(&pathsSlice, PathsFlag, PathsShortFlag, []string{}, "paths to split the OpenAPI document")
+	rootCmd.PersistentFlags().StringSliceVarP(&tagsSlice, TagsFlag, TagsShortFlag, []string{}, "tags of the operations to split the OpenAPI document")
+	rootCmd.PersistentFlags().StringSliceVar(&operationsSlice, OperationsFlag, []string{}, "operationIds of the operations to split the OpenAPI document")
+	rootCmd.PersistentFlags().StringSliceVar(&excludePathsSlice, ExcludePathsFlag, []string{}, "paths to remove from the OpenAPI document")
+	rootCmd.PersistentFlags().StringSliceVar(&keepOpsSlice, KeepOpsFlag, []string{}, "keep only the operations whose extension matches (e.g. x-audience=public|partner or x-beta)")
+	rootCmd.PersistentFlags().StringSliceVar(&dropOpsSlice, DropOpsFlag, []string{}, "remove the operations whose extension matches (e.g. x-internal=true or x-stability=beta)")
+	rootCmd.PersistentFlags().BoolVar(&dropDeprecated, DropDeprecatedFlag, false, "remove the deprecated operations, parameters and schema properties")
+	rootCmd.PersistentFlags().StringVar(&explodeBy, ExplodeFlag, "", "write one document per path, tag or operation into the output directory (path, tag or operation)")
+	rootCmd.PersistentFlags().StringVar(&explodeTemplate, ExplodeTemplateFlag, "", "file name template of the exploded documents (e.g. {{.Tag}}/{{.OperationID}}.yaml, default {{.Name}}.{{.Ext}})")
 	rootCmd.PersistentFlags().BoolVarP(&rmEnable, RmExtsFlag, RmExtsShortFlag, false, "enable removing extensions from the OpenAPI document")
+	rootCmd.PersistentFlags().StringVar(&inputFmt, InputFormatFlag, "auto", "format of the input file (auto, yaml or json)")
+	rootCmd.PersistentFlags().StringVar(&outputVersion, OutputVersionFlag, "3", "major version of the output document (3 for OpenAPI 3 or 2 for Swagger 2.0)")
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -61,8 +115,11 @@ func main() {
 func RunE(cmd *cobra.Command, args []string) error {
 
 	var (
-		cfg       *config.Config
-		endpoints []config.Endpoint
+		cfg              *config.Config
+		endpoints        []config.Endpoint
+		excludeEndpoints []config.Endpoint
+		extRules         []config.ExtensionRule
+		extMappings      []config.ExtensionMapping
 	)
 	if configFile != "" {
 		var err error
@@ -74,49 +131,155 @@ func RunE(cmd *cobra.Command, args []string) error {
 		if cfg.Input.Path != "" {
 			inputPath = cfg.Input.Path
 		}
+		if cfg.Input.Format != "" {
+			inputFmt = cfg.Input.Format
+		}
 		if cfg.Output.Path != "" {
 			outputPath = cfg.Output.Path
 		}
 		if cfg.Output.Format != "" {
 			outputFmt = cfg.Output.Format
 		}
+		if cfg.Output.Version != "" {
+			outputVersion = cfg.Output.Version
+		}
+		if cfg.Output.Explode.By != "" {
+			explodeBy = cfg.Output.Explode.By
+		}
+		if cfg.Output.Explode.Template != "" {
+			explodeTemplate = cfg.Output.Explode.Template
+		}
 		if cfg.RmExts.Enable {
 			rmEnable = cfg.RmExts.Enable
 			if len(cfg.RmExts.Excludes) > 0 {
 				excludesSlice = cfg.RmExts.Excludes
 			}
+			if len(cfg.RmExts.Drop) > 0 {
+				dropExtsSlice = cfg.RmExts.Drop
+			}
+			extRules = cfg.RmExts.Rules
 		}
+		extMappings = cfg.RenameExts
 		if cfg.Sp.Enable && len(cfg.Sp.Endpoints) > 0 {
 			endpoints = cfg.Sp.Endpoints
 		}
+		if cfg.Sp.Enable && len(cfg.Sp.Exclude) > 0 {
+			excludeEndpoints = cfg.Sp.Exclude
+		}
+		if cfg.Sp.Enable && len(cfg.Sp.Tags) > 0 {
+			tagsSlice = cfg.Sp.Tags
+		}
+		if cfg.Sp.Enable && len(cfg.Sp.Operations) > 0 {
+			operationsSlice = cfg.Sp.Operations
+		}
+		if cfg.Sp.Enable && len(cfg.Sp.KeepOps) > 0 {
+			keepOpsSlice = cfg.Sp.KeepOps
+		}
+		if cfg.Sp.Enable && len(cfg.Sp.DropOps) > 0 {
+			dropOpsSlice = cfg.Sp.DropOps
+		}
+		if cfg.Sp.Enable && cfg.Sp.DropDeprecated {
+			dropDeprecated = cfg.Sp.DropDeprecated
+		}
 	}
 
 	if inputPath == "" {
 		return fmt.Errorf("Error: input file path must be provided via flag or config file")
 	}
-	if outputPath == "" {
-		return fmt.Errorf("Error: output file path must be provided via flag or config file")
+	if inputFmt != "" && inputFmt != "auto" && inputFmt != "yaml" && inputFmt != "json" {
+		return fmt.Errorf("Error: input format must be either 'auto', 'yaml' or 'json'")
 	}
-	if outputFmt != "yaml" && outputFmt != "json" {
+	if outputFmt != "" && outputFmt != "yaml" && outputFmt != "json" {
 		return fmt.Errorf("Error: output format must be either 'yaml' or 'json'")
 	}
-	if len(excludesSlice) != 0 {
+	if outputVersion != "3" && outputVersion != "2" {
+		return fmt.Errorf("Error: output version must be either '3' or '2'")
+	}
+	if explodeBy != "" && explodeBy != utils.ExplodeByPath && explodeBy != utils.ExplodeByTag && explodeBy != utils.ExplodeByOperation {
+		return fmt.Errorf("Error: explode must be either 'path', 'tag' or 'operation'")
+	}
+	if explodeBy != "" && (outputPath == "" || outputPath == StdioPath) {
+		return fmt.Errorf("Error: output directory must be provided via flag or config file when exploding")
+	}
+	if len(excludesSlice) != 0 && len(dropExtsSlice) != 0 {
+		return fmt.Errorf("Error: extensions to keep (excludes) and extensions to drop (drop-exts) cannot be given together")
+	}
+	if len(excludesSlice) != 0 || len(dropExtsSlice) != 0 {
 		rmEnable = true
 	}
+	keepExts, err := utils.CompileExtensionPatterns(excludesSlice)
+	if err != nil {
+		return fmt.Errorf("Error: %w", err)
+	}
+	dropExts, err := utils.CompileExtensionPatterns(dropExtsSlice)
+	if err != nil {
+		return fmt.Errorf("Error: %w", err)
+	}
+	rules, err := extensionRules(extRules)
+	if err != nil {
+		return err
+	}
+	mappings, err := extensionMappings(renameExtsSlice, extMappings)
+	if err != nil {
+		return err
+	}
+	keepOps, err := extensionMatchers(keepOpsSlice)
+	if err != nil {
+		return err
+	}
+	dropOps, err := extensionMatchers(dropOpsSlice)
+	if err != nil {
+		return err
+	}
+
+	var source *openapi3.T
 
 	var (
-		source *openapi3.T
-		err    error
+		f         []byte
+		location  *url.URL
+		inputName = inputPath
 	)
-
-	f, err := os.ReadFile(inputPath)
+	if inputPath == StdioPath {
+		inputName = "stdin"
+		f, err = io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("Error reading input from stdin: %w", err)
+		}
+	} else {
+		f, err = os.ReadFile(inputPath)
+		if err != nil {
+			return fmt.Errorf("Error reading input file '%s': %w", inputPath, err)
+		}
+		// The input location is passed along so relative external refs
+		// (e.g. "./schemas/User.yaml") can be resolved against it.
+		location = &url.URL{Path: filepath.ToSlash(inputPath)}
+	}
+	format, err := utils.CheckSyntax(f, inputFmt)
 	if err != nil {
-		return fmt.Errorf("Error reading input file '%s': %w", inputPath, err)
+		return fmt.Errorf("Error parsing input '%s': %w", inputName, err)
+	}
+	if outputFmt == "" {
+		outputFmt = "yaml"
+		if inputPath == StdioPath {
+			// There is no file name to go by, so the output keeps the format of the piped document
+			outputFmt = format
+		}
 	}
 	loader := openapi3.NewLoader()
-	doc, err := loader.LoadFromData(f)
+	loader.IsExternalRefsAllowed = true
+	// Swagger 2.0 documents are converted so the rest of the pipeline only deals with OpenAPI 3
+	doc, err := utils.LoadDocument(loader, f, location)
 	if err != nil {
-		return fmt.Errorf("Error loading OpenAPI document from '%s': %w", inputPath, err)
+		return fmt.Errorf("Error loading OpenAPI document from '%s': %w", inputName, err)
+	}
+	if err := utils.Bundle(doc); err != nil {
+		return fmt.Errorf("Error bundling external references of '%s': %w", inputName, err)
+	}
+	if len(mappings) > 0 {
+		// Renaming comes first, so the filters and the extensions to keep or drop use the new names
+		if err := utils.RenameExtensions(doc, mappings); err != nil {
+			return fmt.Errorf("Error renaming extensions: %w", err)
+		}
 	}
 
 	if len(endpoints) == 0 && len(pathsSlice) > 0 {
@@ -127,53 +290,218 @@ func RunE(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(endpoints) > 0 {
-		targets := map[string][]string{}
-		for _, endpoint := range endpoints {
-			if endpoint.Path == "" {
-				continue
-			}
-			targets[endpoint.Path] = endpoint.Methods
-		}
-
+		targets := endpointTargets(endpoints)
 		source, err = utils.SplitByPath(doc, targets)
 		if err != nil {
 			return fmt.Errorf("Error splitting OpenAPI document by path: %w", err)
 		}
+		if err := warnUnmatchedPatterns(doc, targets); err != nil {
+			return fmt.Errorf("Error splitting OpenAPI document by path: %w", err)
+		}
 	} else {
 		source = doc
 	}
-
-	if rmEnable {
-		// remove extensions
-		keep := map[string]struct{}{}
-		for _, exclude := range excludesSlice {
-			if exclude == "" {
-				continue
-			}
-			keep[exclude] = struct{}{}
+	if len(tagsSlice) > 0 {
+		source, err = utils.SplitByTag(source, tagsSlice)
+		if err != nil {
+			return fmt.Errorf("Error splitting OpenAPI document by tag: %w", err)
 		}
-		utils.RemoveExtensions(source, keep)
 	}
-	var data []byte
-	switch outputFmt {
-	case "yaml":
-		out, err := source.MarshalYAML()
+	if len(operationsSlice) > 0 {
+		source, err = utils.SplitByOperationID(source, operationsSlice)
+		if err != nil {
+			return fmt.Errorf("Error splitting OpenAPI document by operationId: %w", err)
+		}
+	}
+	if len(excludeEndpoints) == 0 && len(excludePathsSlice) > 0 {
+		for _, path := range excludePathsSlice {
+			excludeEndpoints = append(excludeEndpoints, config.Endpoint{Path: path})
+		}
+	}
+	if len(excludeEndpoints) > 0 {
+		targets := endpointTargets(excludeEndpoints)
+		excluded, err := utils.ExcludePaths(source, targets)
 		if err != nil {
-			return fmt.Errorf("Error marshalling OpenAPI document: %w", err)
+			return fmt.Errorf("Error excluding paths from OpenAPI document: %w", err)
 		}
-		data, err = yaml.Marshal(out)
+		if err := warnUnmatchedPatterns(source, targets); err != nil {
+			return fmt.Errorf("Error excluding paths from OpenAPI document: %w", err)
+		}
+		source = excluded
+	}
+	if len(keepOps) > 0 || len(dropOps) > 0 {
+		// The extensions are read before RemoveExtensions strips them
+		source, err = utils.FilterOperationsByExtension(source, keepOps, dropOps)
 		if err != nil {
-			return fmt.Errorf("Error marshalling OpenAPI document to YAML: %w", err)
+			return fmt.Errorf("Error filtering operations by extension: %w", err)
 		}
-	case "json":
-		data, err = source.MarshalJSON()
+	}
+	if dropDeprecated {
+		source, err = utils.DropDeprecated(source)
 		if err != nil {
-			return fmt.Errorf("Error marshalling OpenAPI document to JSON: %w", err)
+			return fmt.Errorf("Error dropping deprecated operations: %w", err)
 		}
 	}
+
+	if rmEnable && len(dropExtsSlice) > 0 {
+		// remove the listed extensions only
+		utils.DropExtensionsWithRules(source, rules, dropExts)
+	} else if rmEnable {
+		// remove extensions
+		utils.RemoveExtensionsWithRules(source, rules, keepExts)
+	}
+	if explodeBy != "" {
+		return writeExploded(source, explodeBy, explodeTemplate, outputPath, outputFmt, outputVersion)
+	}
+	data, err := marshalDocument(source, outputFmt, outputVersion)
+	if err != nil {
+		return err
+	}
+	if outputPath == "" || outputPath == StdioPath {
+		if _, err := stdout.Write(data); err != nil {
+			return fmt.Errorf("Error writing output to stdout: %w", err)
+		}
+		return nil
+	}
 	if err := os.WriteFile(outputPath, data, 0644); err != nil {
 		return fmt.Errorf("Error writing output file '%s': %w", outputPath, err)
 	}
 
 	return nil // Success
 }
+
+// endpointTargets returns the path patterns and methods of the endpoints, as
+// expected by utils.SplitByPath and utils.ExcludePaths.
+func endpointTargets(endpoints []config.Endpoint) map[string][]string {
+	targets := map[string][]string{}
+	for _, endpoint := range endpoints {
+		if endpoint.Path == "" {
+			continue
+		}
+		targets[endpoint.Path] = endpoint.Methods
+	}
+	return targets
+}
+
+// extensionMatchers parses the extension matcher expressions of the keep-ops
+// and drop-ops flags, e.g. "x-audience=public|partner".
+func extensionMatchers(exprs []string) ([]utils.ExtensionMatcher, error) {
+	var matchers []utils.ExtensionMatcher
+	for _, expr := range exprs {
+		if expr == "" {
+			continue
+		}
+		matcher, err := utils.ParseExtensionMatcher(expr)
+		if err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// extensionMappings parses the from=to mappings of the rename-exts flag (e.g.
+// "x-nullable=nullable"), or checks those of the config file when it has any.
+func extensionMappings(exprs []string, configs []config.ExtensionMapping) ([]utils.ExtensionMapping, error) {
+	var mappings []utils.ExtensionMapping
+	if len(configs) > 0 {
+		for _, cfg := range configs {
+			mapping, err := utils.NewExtensionMapping(cfg.From, cfg.To)
+			if err != nil {
+				return nil, fmt.Errorf("Error: %w", err)
+			}
+			mappings = append(mappings, mapping)
+		}
+		return mappings, nil
+	}
+	for _, expr := range exprs {
+		if expr == "" {
+			continue
+		}
+		mapping, err := utils.ParseExtensionMapping(expr)
+		if err != nil {
+			return nil, fmt.Errorf("Error: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// extensionRules compiles the rm-exts rules of the config file.
+func extensionRules(configs []config.ExtensionRule) (utils.ExtensionRules, error) {
+	var rules utils.ExtensionRules
+	for i, cfg := range configs {
+		rule, err := utils.NewExtensionRule(cfg.Kinds, cfg.Pointers, cfg.Keep, cfg.Drop)
+		if err != nil {
+			return nil, fmt.Errorf("Error: rm-exts rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// warnUnmatchedPatterns writes a warning to stderr for every target pattern
+// that matches no path of the document.
+func warnUnmatchedPatterns(doc *openapi3.T, targets map[string][]string) error {
+	unmatched, err := utils.UnmatchedPathPatterns(doc, targets)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range unmatched {
+		fmt.Fprintf(stderr, "Warning: path pattern '%s' matched no path\n", pattern)
+	}
+	return nil
+}
+
+// marshalDocument encodes the document in the given format ("yaml" or "json"),
+// converting it to Swagger 2.0 first when the output version is "2".
+func marshalDocument(doc *openapi3.T, format, version string) ([]byte, error) {
+	if version == "2" {
+		doc2, err := utils.ToSwagger2(doc)
+		if err != nil {
+			return nil, fmt.Errorf("Error converting OpenAPI document to Swagger 2.0: %w", err)
+		}
+		switch format {
+		case "yaml":
+			data, err := oasyaml.Marshal(doc2)
+			if err != nil {
+				return nil, fmt.Errorf("Error marshalling Swagger 2.0 document to YAML: %w", err)
+			}
+			return data, nil
+		default:
+			data, err := doc2.MarshalJSON()
+			if err != nil {
+				return nil, fmt.Errorf("Error marshalling Swagger 2.0 document to JSON: %w", err)
+			}
+			return data, nil
+		}
+	}
+
+	var (
+		out any
+		err error
+	)
+	if strings.HasPrefix(doc.OpenAPI, "3.1") {
+		// Restore the OpenAPI 3.1 forms rewritten when the document was loaded
+		out, err = utils.MarshalOpenAPI31(doc)
+	} else {
+		out, err = doc.MarshalYAML()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling OpenAPI document: %w", err)
+	}
+	switch format {
+	case "yaml":
+		data, err := yaml.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("Error marshalling OpenAPI document to YAML: %w", err)
+		}
+		return data, nil
+	default:
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("Error marshalling OpenAPI document to JSON: %w", err)
+		}
+		return data, nil
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,15 +14,31 @@ import (
 
 // runTestMain is a helper function to execute RunE with specified configurations.
 // It resets global flags before each run.
-func runTestMain(t *testing.T, configFilePathVal, inputPathVal, outputPathVal, outputFmtVal string, excludesVal []string, pathsVal []string, rmEnableVal bool) error {
-	// Reset global flags to defaults or empty states
+// resetFlags resets the global flags to their defaults or empty states.
+func resetFlags() {
 	configFile = ""
 	inputPath = ""
 	outputPath = ""
-	outputFmt = "yaml" // Default value in main.go
+	outputFmt = "" // Default value in main.go
 	excludesSlice = nil
+	dropExtsSlice = nil
+	renameExtsSlice = nil
 	pathsSlice = nil
+	tagsSlice = nil
+	operationsSlice = nil
+	excludePathsSlice = nil
+	keepOpsSlice = nil
+	dropOpsSlice = nil
+	dropDeprecated = false
+	explodeBy = ""
+	explodeTemplate = ""
 	rmEnable = false
+	outputVersion = "3" // Default value in main.go
+	inputFmt = "auto"   // Default value in main.go
+}
+
+func runTestMain(t *testing.T, configFilePathVal, inputPathVal, outputPathVal, outputFmtVal string, excludesVal []string, pathsVal []string, rmEnableVal bool) error {
+	resetFlags()
 
 	// Set global flags based on test case parameters
 	if configFilePathVal != "" {
@@ -355,6 +373,219 @@ func TestRunE_RemoveExtensions(t *testing.T) {
 	assert.NotContains(t, outputStr, "x-remove-me", "Extension x-remove-me should be removed")
 }
 
+func TestRunE_RemoveExtensionsPatterns(t *testing.T) {
+	out := redirectStdio(t, simpleOpenAPIForConfigTest)
+	resetFlags()
+	inputPath, outputPath, excludesSlice = StdioPath, StdioPath, []string{"x-go-*"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-go-type", "Extension matching x-go-* should be present")
+	assert.NotContains(t, out.String(), "x-remove-me", "Extension x-remove-me should be removed")
+
+	out = redirectStdio(t, simpleOpenAPIForConfigTest)
+	resetFlags()
+	inputPath, outputPath, excludesSlice = StdioPath, StdioPath, []string{"re:-me$"}
+	runErr = RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-remove-me", "Extension matching re:-me$ should be present")
+	assert.NotContains(t, out.String(), "x-go-type", "Extension x-go-type should be removed")
+
+	redirectStdio(t, simpleOpenAPIForConfigTest)
+	resetFlags()
+	inputPath, excludesSlice = StdioPath, []string{"re:^x-(go"}
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "invalid extension pattern")
+}
+
+func TestRunE_DropExtensions(t *testing.T) {
+	out := redirectStdio(t, simpleOpenAPIForConfigTest)
+	resetFlags()
+	inputPath, outputPath, dropExtsSlice = StdioPath, StdioPath, []string{"x-remove-*"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-go-type", "Extension x-go-type should be kept")
+	assert.NotContains(t, out.String(), "x-remove-me", "Extension matching x-remove-* should be removed")
+
+	redirectStdio(t, simpleOpenAPIForConfigTest)
+	resetFlags()
+	inputPath, excludesSlice, dropExtsSlice = StdioPath, []string{"x-go-type"}, []string{"x-remove-me"}
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "cannot be given together")
+}
+
+func TestRunE_DropExtensionsConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	assert.NoError(t, os.WriteFile(inputFilePath, []byte(simpleOpenAPIForConfigTest), 0644))
+
+	assert.NoError(t, os.WriteFile(configFilePath, []byte(`
+rm-exts:
+  enable: true
+  drop: ["x-remove-me"]
+`), 0644))
+	out := redirectStdio(t, "")
+	resetFlags()
+	configFile, inputPath = configFilePath, inputFilePath
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-go-type", "Extension x-go-type should be kept")
+	assert.NotContains(t, out.String(), "x-remove-me", "Extension x-remove-me should be removed")
+
+	assert.NoError(t, os.WriteFile(configFilePath, []byte(`
+rm-exts:
+  enable: true
+  excludes: ["x-go-type"]
+  drop: ["x-remove-me"]
+`), 0644))
+	redirectStdio(t, "")
+	resetFlags()
+	configFile, inputPath = configFilePath, inputFilePath
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "cannot be given together")
+}
+
+const extensionRulesOpenAPIYAML = `
+openapi: 3.0.3
+info:
+  title: Extension Rules API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      x-go-type: ListUsers
+      x-codeSamples: [{lang: go, source: client.ListUsers()}]
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    User:
+      type: object
+      x-go-type: User
+      x-codeSamples: user
+`
+
+func TestRunE_ExtensionRulesConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	assert.NoError(t, os.WriteFile(inputFilePath, []byte(extensionRulesOpenAPIYAML), 0644))
+
+	assert.NoError(t, os.WriteFile(configFilePath, []byte(`
+rm-exts:
+  enable: true
+  rules:
+    - kinds: [schema]
+      keep: [x-go-type]
+    - pointers: ["/paths/**/get"]
+      keep: [x-codeSamples]
+`), 0644))
+	out := redirectStdio(t, "")
+	resetFlags()
+	configFile, inputPath = configFilePath, inputFilePath
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var output map[string]any
+	assert.NoError(t, yaml.Unmarshal(out.Bytes(), &output))
+	operation := output["paths"].(map[string]any)["/users"].(map[string]any)["get"].(map[string]any)
+	assert.Contains(t, operation, "x-codeSamples", "x-codeSamples should be kept on operations")
+	assert.NotContains(t, operation, "x-go-type", "x-go-type should be removed from operations")
+	user := output["components"].(map[string]any)["schemas"].(map[string]any)["User"].(map[string]any)
+	assert.Contains(t, user, "x-go-type", "x-go-type should be kept on schemas")
+	assert.NotContains(t, user, "x-codeSamples", "x-codeSamples should be removed from schemas")
+
+	assert.NoError(t, os.WriteFile(configFilePath, []byte(`
+rm-exts:
+  enable: true
+  rules:
+    - kinds: [schemas]
+      keep: [x-go-type]
+`), 0644))
+	redirectStdio(t, "")
+	resetFlags()
+	configFile, inputPath = configFilePath, inputFilePath
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "rm-exts rule 1: invalid extension rule: unknown object kind 'schemas'")
+}
+
+const renameExtensionsOpenAPIYAML = `
+openapi: 3.0.3
+info:
+  title: Rename Extensions API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      x-go-name: ListUsers
+      x-internal: true
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  name:
+                    type: string
+                    x-nullable: true
+`
+
+func TestRunE_RenameExtensions(t *testing.T) {
+	out := redirectStdio(t, renameExtensionsOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath = StdioPath, StdioPath
+	renameExtsSlice = []string{"x-go-name=x-oapi-codegen-extra-tags", "x-nullable=nullable", "x-internal=x-private"}
+	// The filters and the kept extensions use the new names
+	dropOpsSlice, excludesSlice = []string{"x-private=false"}, []string{"x-oapi-codegen-*"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-oapi-codegen-extra-tags: ListUsers")
+	assert.Contains(t, out.String(), "nullable: true")
+	assert.NotContains(t, out.String(), "x-go-name")
+	assert.NotContains(t, out.String(), "x-nullable")
+	assert.NotContains(t, out.String(), "x-private", "x-private is not in the kept extensions")
+
+	redirectStdio(t, renameExtensionsOpenAPIYAML)
+	resetFlags()
+	inputPath, renameExtsSlice = StdioPath, []string{"x-go-name=title"}
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "invalid extension mapping")
+}
+
+func TestRunE_RenameExtensionsConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	assert.NoError(t, os.WriteFile(inputFilePath, []byte(renameExtensionsOpenAPIYAML), 0644))
+	assert.NoError(t, os.WriteFile(configFilePath, []byte(`
+rename-exts:
+  - from: x-go-name
+    to: x-oapi-codegen-extra-tags
+  - from: x-nullable
+    to: nullable
+`), 0644))
+
+	out := redirectStdio(t, "")
+	resetFlags()
+	configFile, inputPath = configFilePath, inputFilePath
+	renameExtsSlice = []string{"x-internal=x-private"} // The config takes precedence
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+	assert.Contains(t, out.String(), "x-oapi-codegen-extra-tags: ListUsers")
+	assert.Contains(t, out.String(), "nullable: true")
+	assert.Contains(t, out.String(), "x-internal: true")
+	assert.NotContains(t, out.String(), "x-nullable")
+}
+
 func TestRunE_SplitPath(t *testing.T) {
 	tempDir := t.TempDir()
 	inputFilePath := filepath.Join(tempDir, "input_paths.yaml")
@@ -386,91 +617,763 @@ func TestRunE_SplitPath(t *testing.T) {
 	assert.NotContains(t, pathsMap, "/api/v1/users", "Path /api/v1/users should be absent")
 }
 
-// Error Condition Test Cases
-
-func TestRunE_ErrorNoInputPath(t *testing.T) {
+func TestRunE_SplitByPathPattern(t *testing.T) {
 	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
 	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIForPathSplit), 0644)
+	assert.NoError(t, err)
 
-	err := runTestMain(t, "", "", outputFilePath, "yaml", nil, nil, false)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "input file path must be provided")
+	origStderr := stderr
+	t.Cleanup(func() { stderr = origStderr })
+	warnings := &bytes.Buffer{}
+	stderr = warnings
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, []string{"/api/*/users", "re:^/admin"}, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(outputData, &yamlData)
+	assert.NoError(t, err, "Output is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/api/v1/users")
+	assert.NotContains(t, pathsMap, "/api/v1/orders")
+	assert.Contains(t, warnings.String(), "path pattern 're:^/admin' matched no path")
+	assert.NotContains(t, warnings.String(), "/api/*/users")
+}
+
+const taggedOpenAPIYAML = `
+openapi: 3.0.0
+info:
+  title: Tagged API
+  version: 1.0.0
+tags:
+  - name: billing
+  - name: users
+paths:
+  /invoices:
+    get:
+      operationId: listInvoices
+      tags: [billing]
+      responses:
+        '200':
+          description: OK
+  /users:
+    get:
+      operationId: listUsers
+      tags: [users]
+      responses:
+        '200':
+          description: OK
+`
+
+func TestRunE_SplitByTagFlag(t *testing.T) {
+	out := redirectStdio(t, taggedOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath, tagsSlice = StdioPath, StdioPath, []string{"billing"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/invoices")
+	assert.NotContains(t, pathsMap, "/users")
+	assert.Len(t, yamlData["tags"], 1)
 }
 
-func TestRunE_ErrorNoOutputPath(t *testing.T) {
+func TestRunE_SplitByConfigTags(t *testing.T) {
 	tempDir := t.TempDir()
 	inputFilePath := filepath.Join(tempDir, "input.yaml")
-	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
 	assert.NoError(t, err)
 
-	runErr := runTestMain(t, "", inputFilePath, "", "yaml", nil, nil, false)
-	assert.Error(t, runErr)
-	assert.Contains(t, runErr.Error(), "output file path must be provided")
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  tags: [users]
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(outputData, &yamlData)
+	assert.NoError(t, err, "Output is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/users")
+	assert.NotContains(t, pathsMap, "/invoices")
 }
 
-func TestRunE_ErrorInvalidOutputFormat(t *testing.T) {
+func TestRunE_SplitByOperationsFlag(t *testing.T) {
+	out := redirectStdio(t, taggedOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath, operationsSlice = StdioPath, StdioPath, []string{"listUsers"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/users")
+	assert.NotContains(t, pathsMap, "/invoices")
+}
+
+func TestRunE_ErrorUnknownConfigOperations(t *testing.T) {
 	tempDir := t.TempDir()
 	inputFilePath := filepath.Join(tempDir, "input.yaml")
 	outputFilePath := filepath.Join(tempDir, "output.yaml")
-	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
 	assert.NoError(t, err)
 
-	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "xml", nil, nil, false) // xml is invalid
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  operations: [listInvoices, createUser]
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
 	assert.Error(t, runErr)
-	assert.Contains(t, runErr.Error(), "output format must be either 'yaml' or 'json'")
+	assert.Contains(t, runErr.Error(), "Error splitting OpenAPI document by operationId")
+	assert.Contains(t, runErr.Error(), "createUser")
+	_, err = os.Stat(outputFilePath)
+	assert.True(t, os.IsNotExist(err), "No output should be written on error")
 }
 
-func TestRunE_ErrorInputFileNonExistent(t *testing.T) {
+func TestRunE_ExcludePathsFlag(t *testing.T) {
+	out := redirectStdio(t, taggedOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath, excludePathsSlice = StdioPath, StdioPath, []string{"/users", "/admin/**"}
+	origStderr := stderr
+	t.Cleanup(func() { stderr = origStderr })
+	warnings := &bytes.Buffer{}
+	stderr = warnings
+
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/invoices")
+	assert.NotContains(t, pathsMap, "/users")
+	assert.Contains(t, warnings.String(), "path pattern '/admin/**' matched no path")
+}
+
+func TestRunE_ConfigExclude(t *testing.T) {
 	tempDir := t.TempDir()
-	inputFilePath := filepath.Join(tempDir, "nonexistent_input.yaml")
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
 	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
+	assert.NoError(t, err)
 
-	err := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, nil, false)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "Error reading input file")
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  exclude:
+    - path: /invoices
+      methods: [get]
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(outputData, &yamlData)
+	assert.NoError(t, err, "Output is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/users")
+	assert.NotContains(t, pathsMap, "/invoices", "Its only method was excluded")
 }
 
-func TestRunE_ErrorConfigFileNonExistent(t *testing.T) {
+const audienceOpenAPIYAML = `
+openapi: 3.0.0
+info:
+  title: Annotated API
+  version: 1.0.0
+paths:
+  /invoices:
+    get:
+      operationId: listInvoices
+      x-audience: public
+      responses:
+        '200':
+          description: OK
+    post:
+      operationId: createInvoice
+      x-audience: public
+      x-stability: beta
+      responses:
+        '201':
+          description: Created
+  /partners:
+    get:
+      operationId: listPartners
+      x-audience: partner
+      responses:
+        '200':
+          description: OK
+`
+
+func TestRunE_FilterOperationsByExtensionFlags(t *testing.T) {
+	out := redirectStdio(t, audienceOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath, rmEnable = StdioPath, StdioPath, true
+	keepOpsSlice, dropOpsSlice = []string{"x-audience=public"}, []string{"x-stability=beta"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.NotContains(t, pathsMap, "/partners")
+	invoices := pathsMap["/invoices"].(map[string]interface{})
+	assert.Contains(t, invoices, "get")
+	assert.NotContains(t, invoices, "post")
+	assert.NotContains(t, out.String(), "x-audience", "The markers are removed after filtering")
+}
+
+func TestRunE_ConfigFilterOperationsByExtension(t *testing.T) {
 	tempDir := t.TempDir()
-	// Don't need input/output for this, as config load is early
-	err := runTestMain(t, filepath.Join(tempDir, "nonexistent_config.yaml"), "", "", "yaml", nil, nil, false)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "Error loading config file")
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(audienceOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  keep-ops: ["x-audience=public|partner"]
+  drop-ops: [x-stability]
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(outputData, &yamlData)
+	assert.NoError(t, err, "Output is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/partners")
+	assert.NotContains(t, pathsMap["/invoices"], "post")
 }
 
-const malformedYAML = `
+func TestRunE_ErrorFilterOperationsByExtension(t *testing.T) {
+	redirectStdio(t, audienceOpenAPIYAML)
+	resetFlags()
+	inputPath, keepOpsSlice = StdioPath, []string{"audience=public"}
+	runErr := RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "invalid extension matcher")
+
+	redirectStdio(t, audienceOpenAPIYAML)
+	resetFlags()
+	inputPath, keepOpsSlice = StdioPath, []string{"x-audience=internal"}
+	runErr = RunE(nil, []string{})
+	assert.ErrorContains(t, runErr, "Error filtering operations by extension")
+}
+
+const deprecatedOpenAPIYAML = `
 openapi: 3.0.0
 info:
-  title: Malformed API
+  title: Deprecated API
   version: 1.0.0
-paths: /hello # This should be a map, not a string
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - name: offset
+          in: query
+          deprecated: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /legacy:
+    get:
+      operationId: legacy
+      deprecated: true
+      responses:
+        '200':
+          description: OK
+components:
+  schemas:
+    User:
+      type: object
+      required: [id, nickname]
+      properties:
+        id:
+          type: string
+        nickname:
+          type: string
+          deprecated: true
 `
 
-func TestRunE_ErrorInvalidInputOpenAPI(t *testing.T) {
+func TestRunE_DropDeprecatedFlag(t *testing.T) {
+	out := redirectStdio(t, deprecatedOpenAPIYAML)
+	resetFlags()
+	inputPath, outputPath, dropDeprecated = StdioPath, StdioPath, true
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/users")
+	assert.NotContains(t, pathsMap, "/legacy")
+	assert.NotContains(t, out.String(), "offset")
+	assert.NotContains(t, out.String(), "nickname")
+}
+
+func TestRunE_ConfigDropDeprecated(t *testing.T) {
 	tempDir := t.TempDir()
-	inputFilePath := filepath.Join(tempDir, "malformed_input.yaml")
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
 	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(deprecatedOpenAPIYAML), 0644)
+	assert.NoError(t, err)
 
-	err := os.WriteFile(inputFilePath, []byte(malformedYAML), 0644)
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  drop-deprecated: true
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
 	assert.NoError(t, err)
 
-	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, nil, false)
-	assert.Error(t, runErr)
-	assert.Contains(t, runErr.Error(), "Error loading OpenAPI document")
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+	assert.NotContains(t, string(outputData), "/legacy")
+	assert.NotContains(t, string(outputData), "nickname")
 }
 
-func TestRunE_ErrorSplitPathNotFound(t *testing.T) {
+func TestRunE_ExplodeByOperation(t *testing.T) {
 	tempDir := t.TempDir()
-	inputFilePath := filepath.Join(tempDir, "input_paths.yaml")
-	outputFilePath := filepath.Join(tempDir, "output_paths.yaml")
-
-	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIForPathSplit), 0644) // Contains /api/v1/users and /api/v1/orders
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputDir := filepath.Join(tempDir, "out")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
 	assert.NoError(t, err)
 
-	runErr := runTestMain(t,
-		"",                                  // configFile
-		inputFilePath,                       // inputPath
-		outputFilePath,                      // outputPath
+	resetFlags()
+	inputPath, outputPath = inputFilePath, outputDir
+	explodeBy, explodeTemplate = "operation", "{{.Tag}}/{{.OperationID}}.yaml"
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	for _, file := range []string{"billing/listInvoices.yaml", "users/listUsers.yaml"} {
+		outputData, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(file)))
+		if !assert.NoError(t, err, "Missing exploded document %s", file) {
+			continue
+		}
+		var yamlData map[string]interface{}
+		err = yaml.Unmarshal(outputData, &yamlData)
+		assert.NoError(t, err, "Exploded document is not valid YAML")
+		assert.Len(t, yamlData["paths"], 1)
+	}
+
+	indexData, err := os.ReadFile(filepath.Join(outputDir, "index.yaml"))
+	assert.NoError(t, err, "Missing index file")
+	var index struct {
+		By        string `yaml:"by"`
+		Documents []struct {
+			File        string `yaml:"file"`
+			Path        string `yaml:"path"`
+			Method      string `yaml:"method"`
+			OperationID string `yaml:"operationId"`
+		} `yaml:"documents"`
+	}
+	err = yaml.Unmarshal(indexData, &index)
+	assert.NoError(t, err, "Index is not valid YAML")
+	assert.Equal(t, "operation", index.By)
+	if assert.Len(t, index.Documents, 2) {
+		assert.Equal(t, "billing/listInvoices.yaml", index.Documents[0].File)
+		assert.Equal(t, "/invoices", index.Documents[0].Path)
+		assert.Equal(t, "GET", index.Documents[0].Method)
+		assert.Equal(t, "listInvoices", index.Documents[0].OperationID)
+	}
+}
+
+func TestRunE_ExplodeByConfigPathJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputDir := filepath.Join(tempDir, "out")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputDir + `
+  format: json
+  explode:
+    by: path
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	entries, err := os.ReadDir(outputDir)
+	assert.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"index.json", "invoices.json", "users.json"}, files)
+
+	var jsonData map[string]interface{}
+	outputData, err := os.ReadFile(filepath.Join(outputDir, "users.json"))
+	assert.NoError(t, err)
+	err = json.Unmarshal(outputData, &jsonData)
+	assert.NoError(t, err, "Exploded document is not valid JSON")
+	assert.Contains(t, jsonData["paths"], "/users")
+}
+
+func TestRunE_ErrorExplode(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		output      string
+		by          string
+		template    string
+		expectedErr string
+	}{
+		{
+			name:        "no output directory",
+			by:          "path",
+			expectedErr: "output directory must be provided",
+		},
+		{
+			name:        "unknown unit",
+			output:      filepath.Join(tempDir, "unknown"),
+			by:          "schema",
+			expectedErr: "explode must be either 'path', 'tag' or 'operation'",
+		},
+		{
+			name:        "colliding file names",
+			output:      filepath.Join(tempDir, "collision"),
+			by:          "operation",
+			template:    "{{.Method}}.yaml",
+			expectedErr: "renders 'get.yaml' for more than one document",
+		},
+		{
+			name:        "file outside the output directory",
+			output:      filepath.Join(tempDir, "outside"),
+			by:          "path",
+			template:    "../{{.Name}}.yaml",
+			expectedErr: "not a file inside the output directory",
+		},
+		{
+			name:        "unknown template field",
+			output:      filepath.Join(tempDir, "field"),
+			by:          "path",
+			template:    "{{.Summary}}.yaml",
+			expectedErr: "Error rendering explode template",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetFlags()
+			inputPath, outputPath = inputFilePath, tc.output
+			explodeBy, explodeTemplate = tc.by, tc.template
+			runErr := RunE(nil, []string{})
+			assert.Error(t, runErr)
+			assert.Contains(t, runErr.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestRunE_ErrorUnknownTag(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(taggedOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+sp:
+  enable: true
+  tags: [shipping]
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "Error splitting OpenAPI document by tag")
+}
+
+// Error Condition Test Cases
+
+func TestRunE_ErrorNoInputPath(t *testing.T) {
+	tempDir := t.TempDir()
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+
+	err := runTestMain(t, "", "", outputFilePath, "yaml", nil, nil, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "input file path must be provided")
+}
+
+// redirectStdio replaces the stdin and stdout used by RunE for the duration of the test.
+func redirectStdio(t *testing.T, input string) *bytes.Buffer {
+	origStdin, origStdout := stdin, stdout
+	t.Cleanup(func() {
+		stdin, stdout = origStdin, origStdout
+	})
+	out := &bytes.Buffer{}
+	stdin = strings.NewReader(input)
+	stdout = out
+	return out
+}
+
+func TestRunE_NoOutputPathWritesStdout(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	out := redirectStdio(t, "")
+	runErr := runTestMain(t, "", inputFilePath, "", "yaml", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	assert.Equal(t, "Simple Test API", yamlData["info"].(map[string]interface{})["title"])
+}
+
+func TestRunE_StdinToStdout(t *testing.T) {
+	out := redirectStdio(t, simpleOpenAPIForPathSplit)
+	runErr := runTestMain(t, "", StdioPath, StdioPath, "", nil, []string{"/api/v1/users"}, true)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/api/v1/users")
+	assert.NotContains(t, pathsMap, "/api/v1/orders")
+	assert.NotContains(t, out.String(), "x-", "Extensions should be removed")
+}
+
+func TestRunE_StdinJSONKeepsFormat(t *testing.T) {
+	input := `{"openapi": "3.0.0", "info": {"title": "Piped API", "version": "1.0.0"}, "paths": {}}`
+	out := redirectStdio(t, input)
+	runErr := runTestMain(t, "", StdioPath, StdioPath, "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var jsonData map[string]interface{}
+	err := json.Unmarshal(out.Bytes(), &jsonData)
+	assert.NoError(t, err, "JSON on stdin should produce JSON on stdout")
+	assert.Equal(t, "Piped API", jsonData["info"].(map[string]interface{})["title"])
+}
+
+func TestRunE_ErrorInvalidStdin(t *testing.T) {
+	out := redirectStdio(t, malformedYAML)
+	runErr := runTestMain(t, "", StdioPath, StdioPath, "yaml", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "Error loading OpenAPI document from 'stdin'")
+	assert.Empty(t, out.String(), "Nothing should be written to stdout on error")
+}
+
+func TestRunE_ErrorInvalidJSONInput(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.json")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	err := os.WriteFile(inputFilePath, []byte("{\n  \"openapi\": \"3.0.0\",\n  \"info\": {,}\n}"), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "json syntax error at line 3, column 12")
+}
+
+func TestRunE_ConfigInputFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	// The input is YAML, so forcing JSON must fail.
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+  format: json
+output:
+  path: ` + outputFilePath + `
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "json syntax error at line")
+
+	configContent = strings.Replace(configContent, "format: json", "format: yaml", 1)
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr = runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+	_, err = os.Stat(outputFilePath)
+	assert.NoError(t, err, "Output file was not created")
+}
+
+func TestRunE_ErrorInvalidInputFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	configFilePath := filepath.Join(tempDir, "config.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+  format: xml
+output:
+  path: ` + outputFilePath + `
+`
+	err = os.WriteFile(configFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, configFilePath, "", "", "", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "input format must be either 'auto', 'yaml' or 'json'")
+}
+
+func TestRunE_ErrorInvalidOutputFormat(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "xml", nil, nil, false) // xml is invalid
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "output format must be either 'yaml' or 'json'")
+}
+
+func TestRunE_ErrorInputFileNonExistent(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "nonexistent_input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+
+	err := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, nil, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error reading input file")
+}
+
+func TestRunE_ErrorConfigFileNonExistent(t *testing.T) {
+	tempDir := t.TempDir()
+	// Don't need input/output for this, as config load is early
+	err := runTestMain(t, filepath.Join(tempDir, "nonexistent_config.yaml"), "", "", "yaml", nil, nil, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Error loading config file")
+}
+
+const malformedYAML = `
+openapi: 3.0.0
+info:
+  title: Malformed API
+  version: 1.0.0
+paths: /hello # This should be a map, not a string
+`
+
+func TestRunE_ErrorInvalidInputOpenAPI(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "malformed_input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+
+	err := os.WriteFile(inputFilePath, []byte(malformedYAML), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "Error loading OpenAPI document")
+}
+
+func TestRunE_ErrorSplitPathNotFound(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input_paths.yaml")
+	outputFilePath := filepath.Join(tempDir, "output_paths.yaml")
+
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIForPathSplit), 0644) // Contains /api/v1/users and /api/v1/orders
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t,
+		"",                                  // configFile
+		inputFilePath,                       // inputPath
+		outputFilePath,                      // outputPath
 		"yaml",                              // outputFmt
 		nil,                                 // excludesSlice
 		[]string{"/api/v1/nonexistentpath"}, // pathsSlice with a path not in the doc
@@ -481,3 +1384,259 @@ func TestRunE_ErrorSplitPathNotFound(t *testing.T) {
 	// To check for specific error type, you might need to adjust RunE or use errors.Is with a sentinel error from utils.
 	// For now, checking the message is a good start.
 }
+
+func TestRunE_BundleExternalRefs(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "api.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+
+	err := os.MkdirAll(filepath.Join(tempDir, "schemas"), 0755)
+	assert.NoError(t, err)
+	err = os.WriteFile(inputFilePath, []byte(`
+openapi: 3.0.0
+info:
+  title: Multi-file API
+  version: 1.0.0
+paths:
+  /users:
+    get:
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: ./schemas/User.yaml
+`), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "schemas", "User.yaml"), []byte(`
+type: object
+properties:
+  id:
+    type: string
+`), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, []string{"/users"}, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+
+	outputStr := string(outputData)
+	assert.Contains(t, outputStr, "#/components/schemas/schemas_User", "External ref should point at the bundled component")
+	assert.NotContains(t, outputStr, "./schemas/User.yaml", "External ref should not remain in the output")
+}
+
+const simpleSwagger2YAML = `
+swagger: "2.0"
+info:
+  title: Legacy API
+  version: 1.0.0
+host: api.example.com
+basePath: /v1
+paths:
+  /users:
+    get:
+      x-remove-me: "should be gone if rmEnable"
+      produces:
+        - application/json
+      responses:
+        200:
+          description: OK
+          schema:
+            $ref: '#/definitions/User'
+  /orders:
+    get:
+      responses:
+        200:
+          description: OK
+definitions:
+  User:
+    type: object
+    properties:
+      id:
+        type: string
+`
+
+func TestRunE_Swagger2Input(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "swagger.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+
+	err := os.WriteFile(inputFilePath, []byte(simpleSwagger2YAML), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "yaml", nil, []string{"/users"}, true)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+
+	var yamlData map[string]interface{}
+	err = yaml.Unmarshal(outputData, &yamlData)
+	assert.NoError(t, err, "Output is not valid YAML")
+	assert.Contains(t, yamlData, "openapi", "Swagger 2.0 input should be converted to OpenAPI 3")
+	assert.NotContains(t, yamlData, "swagger")
+
+	pathsMap := yamlData["paths"].(map[string]interface{})
+	assert.Contains(t, pathsMap, "/users")
+	assert.NotContains(t, pathsMap, "/orders")
+
+	outputStr := string(outputData)
+	assert.Contains(t, outputStr, "#/components/schemas/User")
+	assert.Contains(t, outputStr, "https://api.example.com/v1")
+	assert.NotContains(t, outputStr, "x-remove-me")
+}
+
+func TestRunE_Swagger2Output(t *testing.T) {
+	tempDir := t.TempDir()
+	cfgFilePath := filepath.Join(tempDir, "config.yaml")
+	inputFilePath := filepath.Join(tempDir, "swagger.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.json")
+
+	err := os.WriteFile(inputFilePath, []byte(simpleSwagger2YAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+  format: json
+  version: "2"
+`
+	err = os.WriteFile(cfgFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, cfgFilePath, "", "", "", nil, nil, false)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+
+	outputStr := string(outputData)
+	assert.Contains(t, outputStr, `"swagger":"2.0"`, "Output should be emitted as Swagger 2.0")
+	assert.Contains(t, outputStr, "#/definitions/User")
+	assert.NotContains(t, outputStr, `"openapi"`)
+}
+
+func TestRunE_ErrorSwagger2OutputWebhooks(t *testing.T) {
+	tempDir := t.TempDir()
+	cfgFilePath := filepath.Join(tempDir, "config.yaml")
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	outputFilePath := filepath.Join(tempDir, "output.yaml")
+	input := `
+openapi: 3.1.0
+info:
+  title: Webhooks
+  version: 1.0.0
+webhooks:
+  ping:
+    post:
+      responses:
+        '200':
+          description: OK
+`
+	err := os.WriteFile(inputFilePath, []byte(input), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + outputFilePath + `
+  version: "2"
+`
+	err = os.WriteFile(cfgFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, cfgFilePath, "", "", "", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "Swagger 2.0 has no webhooks")
+	assert.NoFileExists(t, outputFilePath)
+}
+
+func TestRunE_ErrorInvalidOutputVersion(t *testing.T) {
+	tempDir := t.TempDir()
+	cfgFilePath := filepath.Join(tempDir, "config.yaml")
+	inputFilePath := filepath.Join(tempDir, "input.yaml")
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPIYAML), 0644)
+	assert.NoError(t, err)
+
+	configContent := `
+input:
+  path: ` + inputFilePath + `
+output:
+  path: ` + filepath.Join(tempDir, "output.yaml") + `
+  version: "4"
+`
+	err = os.WriteFile(cfgFilePath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, cfgFilePath, "", "", "", nil, nil, false)
+	assert.Error(t, runErr)
+	assert.Contains(t, runErr.Error(), "output version must be either '3' or '2'")
+}
+
+const simpleOpenAPI31YAML = `
+openapi: 3.1.0
+info:
+  title: OpenAPI 3.1 API
+  version: 1.0.0
+webhooks:
+  ping:
+    post:
+      x-remove-me: "should be gone if rmEnable"
+      responses:
+        '200':
+          description: OK
+paths:
+  /items:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: [integer, "null"]
+            exclusiveMinimum: 0
+      responses:
+        '200':
+          description: OK
+`
+
+func TestRunE_OpenAPI31(t *testing.T) {
+	tempDir := t.TempDir()
+	inputFilePath := filepath.Join(tempDir, "input31.yaml")
+	outputFilePath := filepath.Join(tempDir, "output31.json")
+
+	err := os.WriteFile(inputFilePath, []byte(simpleOpenAPI31YAML), 0644)
+	assert.NoError(t, err)
+
+	runErr := runTestMain(t, "", inputFilePath, outputFilePath, "json", nil, []string{"/items", "webhook:ping"}, true)
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	outputData, err := os.ReadFile(outputFilePath)
+	assert.NoError(t, err, "Failed to read output file")
+
+	outputStr := string(outputData)
+	assert.Contains(t, outputStr, `"openapi":"3.1.0"`)
+	assert.Contains(t, outputStr, `"webhooks"`, "targeted webhooks should survive splitting")
+	assert.Contains(t, outputStr, `"type":["integer","null"]`)
+	assert.Contains(t, outputStr, `"exclusiveMinimum":0`, "exclusiveMinimum should be emitted in the 3.1 numeric form")
+	assert.NotContains(t, outputStr, "x-remove-me")
+}
+
+func TestRunE_SplitWebhook(t *testing.T) {
+	out := redirectStdio(t, simpleOpenAPI31YAML)
+	resetFlags()
+	inputPath, outputPath, pathsSlice = StdioPath, StdioPath, []string{"webhook:ping"}
+	runErr := RunE(nil, []string{})
+	assert.NoError(t, runErr, "RunE returned an error")
+
+	var yamlData map[string]interface{}
+	err := yaml.Unmarshal(out.Bytes(), &yamlData)
+	assert.NoError(t, err, "Output on stdout is not valid YAML")
+	assert.Empty(t, yamlData["paths"])
+	assert.Contains(t, yamlData["webhooks"], "ping")
+}
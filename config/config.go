@@ -8,10 +8,11 @@ import (
 )
 
 type Config struct {
-	Input  InputConfig  `yaml:"input"`
-	Output OutputConfig `yaml:"output"`
-	RmExts RmExtsConfig `yaml:"rm-exts"`
-	Sp     SpConfig     `yaml:"sp"`
+	Input      InputConfig        `yaml:"input"`
+	Output     OutputConfig       `yaml:"output"`
+	RmExts     RmExtsConfig       `yaml:"rm-exts"`
+	RenameExts []ExtensionMapping `yaml:"rename-exts"`
+	Sp         SpConfig           `yaml:"sp"`
 }
 
 type InputConfig struct {
@@ -20,18 +21,49 @@ type InputConfig struct {
 }
 
 type OutputConfig struct {
-	Path   string `yaml:"path"`
-	Format string `yaml:"format"`
+	Path    string        `yaml:"path"`
+	Format  string        `yaml:"format"`
+	Version string        `yaml:"version"`
+	Explode ExplodeConfig `yaml:"explode"`
+}
+
+type ExplodeConfig struct {
+	By       string `yaml:"by"`
+	Template string `yaml:"template"`
 }
 
 type RmExtsConfig struct {
-	Enable   bool     `yaml:"enable"`
-	Excludes []string `yaml:"excludes"`
+	Enable   bool            `yaml:"enable"`
+	Excludes []string        `yaml:"excludes"`
+	Drop     []string        `yaml:"drop"`
+	Rules    []ExtensionRule `yaml:"rules"`
+}
+
+// ExtensionRule keeps or drops extensions on the objects of some kinds (e.g.
+// schema or operation) or at some JSON pointers (e.g. /paths/**/get) only.
+type ExtensionRule struct {
+	Kinds    []string `yaml:"kinds"`
+	Pointers []string `yaml:"pointers"`
+	Keep     []string `yaml:"keep"`
+	Drop     []string `yaml:"drop"`
+}
+
+// ExtensionMapping renames the extension From to To, or promotes it into the
+// standard field To (e.g. x-nullable to nullable).
+type ExtensionMapping struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
 }
 
 type SpConfig struct {
-	Enable    bool       `yaml:"enable"`
-	Endpoints []Endpoint `yaml:"endpoints"`
+	Enable         bool       `yaml:"enable"`
+	Endpoints      []Endpoint `yaml:"endpoints"`
+	Tags           []string   `yaml:"tags"`
+	Operations     []string   `yaml:"operations"`
+	Exclude        []Endpoint `yaml:"exclude"`
+	KeepOps        []string   `yaml:"keep-ops"`
+	DropOps        []string   `yaml:"drop-ops"`
+	DropDeprecated bool       `yaml:"drop-deprecated"`
 }
 
 type Endpoint struct {
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Bundle moves every externally referenced object (e.g. "./schemas/User.yaml")
+// into the components section of the document and rewrites the references to
+// point at the local copy, so the result is a single self-contained document.
+// The document must have been loaded with external references allowed.
+// Names are derived from the referenced file and are made unique against the
+// components that already exist in the document.
+func Bundle(doc *openapi3.T) error {
+	if doc == nil {
+		return ErrOpenAPINotFound
+	}
+
+	// InternalizeRefs inlines the top level of every component, which would also
+	// turn local aliases (a component that is only a "#/components/..." ref) into
+	// copies. Remember them so they can be restored afterwards.
+	aliases := localComponentAliases(doc.Components)
+
+	resolver := &bundleNameResolver{
+		doc:   doc,
+		names: map[string]string{},
+		taken: map[string]struct{}{},
+	}
+	doc.InternalizeRefs(context.Background(), resolver.resolve)
+
+	restoreComponentAliases(doc.Components, aliases)
+	return nil
+}
+
+// bundleNameResolver assigns one component name per external target and makes
+// sure two different targets never share a name.
+type bundleNameResolver struct {
+	doc *openapi3.T
+	// names maps "<collection> <absolute ref>" to the assigned component name.
+	names map[string]string
+	// taken holds "<collection>/<name>" of every name handed out so far.
+	taken map[string]struct{}
+}
+
+func (r *bundleNameResolver) resolve(doc *openapi3.T, ref openapi3.ComponentRef) string {
+	collection := ref.CollectionName()
+	key := collection + " " + ref.RefString()
+	if refPath := ref.RefPath(); refPath != nil {
+		key = collection + " " + refPath.String()
+	}
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+
+	base := openapi3.DefaultRefNameResolver(doc, ref)
+	// References that point back into the root document keep their name.
+	if _, inRoot := openapi3.ReferencesComponentInRootDocument(doc, ref); inRoot {
+		r.names[key] = base
+		r.taken[collection+"/"+base] = struct{}{}
+		return base
+	}
+
+	name := base
+	for i := 2; r.isTaken(collection, name); i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	r.names[key] = name
+	r.taken[collection+"/"+name] = struct{}{}
+	return name
+}
+
+func (r *bundleNameResolver) isTaken(collection, name string) bool {
+	if _, ok := r.taken[collection+"/"+name]; ok {
+		return true
+	}
+	return componentExists(r.doc.Components, collection, name)
+}
+
+// componentExists reports whether the components section already holds an
+// entry with the given name in the given collection (e.g. "schemas").
+func componentExists(components *openapi3.Components, collection, name string) bool {
+	if components == nil {
+		return false
+	}
+	var ok bool
+	switch collection {
+	case "schemas":
+		_, ok = components.Schemas[name]
+	case "parameters":
+		_, ok = components.Parameters[name]
+	case "headers":
+		_, ok = components.Headers[name]
+	case "requestBodies":
+		_, ok = components.RequestBodies[name]
+	case "responses":
+		_, ok = components.Responses[name]
+	case "securitySchemes":
+		_, ok = components.SecuritySchemes[name]
+	case "examples":
+		_, ok = components.Examples[name]
+	case "links":
+		_, ok = components.Links[name]
+	case "callbacks":
+		_, ok = components.Callbacks[name]
+	}
+	return ok
+}
+
+// componentAliases holds the local refs of top-level components, keyed by
+// collection and then by component name.
+type componentAliases map[string]map[string]string
+
+func localComponentAliases(components *openapi3.Components) componentAliases {
+	aliases := componentAliases{}
+	if components == nil {
+		return aliases
+	}
+	add := func(collection, name, ref string) {
+		if !isLocalComponentRef(ref) {
+			return
+		}
+		if aliases[collection] == nil {
+			aliases[collection] = map[string]string{}
+		}
+		aliases[collection][name] = ref
+	}
+	for name, schema := range components.Schemas {
+		if schema != nil {
+			add("schemas", name, schema.Ref)
+		}
+	}
+	for name, param := range components.Parameters {
+		if param != nil {
+			add("parameters", name, param.Ref)
+		}
+	}
+	for name, requestBody := range components.RequestBodies {
+		if requestBody != nil {
+			add("requestBodies", name, requestBody.Ref)
+		}
+	}
+	for name, callback := range components.Callbacks {
+		if callback != nil {
+			add("callbacks", name, callback.Ref)
+		}
+	}
+	return aliases
+}
+
+func restoreComponentAliases(components *openapi3.Components, aliases componentAliases) {
+	if components == nil {
+		return
+	}
+	for name, ref := range aliases["schemas"] {
+		if schema := components.Schemas[name]; schema != nil {
+			schema.Ref = ref
+		}
+	}
+	for name, ref := range aliases["parameters"] {
+		if param := components.Parameters[name]; param != nil {
+			param.Ref = ref
+		}
+	}
+	for name, ref := range aliases["requestBodies"] {
+		if requestBody := components.RequestBodies[name]; requestBody != nil {
+			requestBody.Ref = ref
+		}
+	}
+	for name, ref := range aliases["callbacks"] {
+		if callback := components.Callbacks[name]; callback != nil {
+			callback.Ref = ref
+		}
+	}
+}
+
+func isLocalComponentRef(ref string) bool {
+	return strings.HasPrefix(ref, "#/components/")
+}
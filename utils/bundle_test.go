@@ -0,0 +1,70 @@
+package utils_test
+
+import (
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// loadBundleTestAPIDoc loads the multi-file document in testdata/bundle with
+// external references allowed.
+func (suite *UtilsTestSuite) loadBundleTestAPIDoc() *openapi3.T {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile("testdata/bundle/api.yaml")
+	if err != nil {
+		suite.T().Fatalf("Failed to load testdata/bundle/api.yaml: %v", err)
+	}
+	return doc
+}
+
+func (suite *UtilsTestSuite) TestBundle() {
+	doc := suite.loadBundleTestAPIDoc()
+
+	err := utils.Bundle(doc)
+	assert.NoError(suite.T(), err)
+
+	out, err := doc.MarshalYAML()
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	str := string(b)
+	assert.NotRegexp(suite.T(), `\$ref: .*\.(yaml|json)`, str, "External references should have been internalized")
+
+	schemas := doc.Components.Schemas
+	assert.Contains(suite.T(), schemas, "schemas_User")
+	assert.Contains(suite.T(), schemas, "schemas_Order", "Order should be pulled in via the path and via User")
+	assert.Contains(suite.T(), doc.Components.Parameters, "parameters_OrderId")
+
+	// Profile.yaml and Profile.json map to the same default name and must not collide.
+	assert.Contains(suite.T(), schemas, "schemas_Profile")
+	assert.Contains(suite.T(), schemas, "schemas_Profile_2")
+	assert.NotSame(suite.T(), schemas["schemas_Profile"].Value, schemas["schemas_Profile_2"].Value)
+
+	// Local aliases are kept as references.
+	assert.Equal(suite.T(), "#/components/schemas/Status", schemas["Alias"].Ref)
+}
+
+func (suite *UtilsTestSuite) TestBundleThenSplit() {
+	doc := suite.loadBundleTestAPIDoc()
+	err := utils.Bundle(doc)
+	assert.NoError(suite.T(), err)
+
+	output, err := utils.SplitByPath(doc, map[string][]string{
+		"/orders/{id}": {},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output.Components.Schemas, "schemas_Order")
+	assert.Contains(suite.T(), output.Components.Parameters, "parameters_OrderId")
+	assert.NotContains(suite.T(), output.Components.Schemas, "schemas_User")
+}
+
+func (suite *UtilsTestSuite) TestBundleNilDocument() {
+	err := utils.Bundle(nil)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/oasdiff/yaml"
+)
+
+// IsSwagger2 reports whether the raw document (JSON or YAML) is a Swagger 2.0
+// document, i.e. whether it has a "swagger" root key.
+func IsSwagger2(data []byte) bool {
+	_, swagger := documentVersions(data)
+	return swagger != ""
+}
+
+// ConvertSwagger2 parses a Swagger 2.0 document (JSON or YAML) and converts it
+// to OpenAPI 3. The loader and location are used to resolve the references of
+// the converted document.
+func ConvertSwagger2(data []byte, loader *openapi3.Loader, location *url.URL) (*openapi3.T, error) {
+	var doc2 openapi2.T
+	if err := yaml.Unmarshal(data, &doc2); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Swagger 2.0 document: %w", err)
+	}
+	if loader == nil {
+		loader = openapi3.NewLoader()
+	}
+	doc, err := openapi2conv.ToV3WithLoader(&doc2, loader, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Swagger 2.0 document to OpenAPI 3: %w", err)
+	}
+	return doc, nil
+}
+
+// ToSwagger2 converts an OpenAPI 3 document to Swagger 2.0. The OpenAPI 3.1
+// webhooks and components pathItems have no Swagger 2.0 form, documents with
+// them are reported with ErrUnconvertibleDocument instead of losing them.
+func ToSwagger2(doc *openapi3.T) (*openapi2.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	if len(webhooks(doc)) > 0 {
+		return nil, fmt.Errorf("%w: Swagger 2.0 has no webhooks", ErrUnconvertibleDocument)
+	}
+	if doc.Components != nil {
+		if pathItems, _ := doc.Components.Extensions["pathItems"].(map[string]any); len(pathItems) > 0 {
+			return nil, fmt.Errorf("%w: Swagger 2.0 has no components pathItems", ErrUnconvertibleDocument)
+		}
+	}
+	if doc.Components == nil {
+		// openapi2conv.FromV3 expects the components to be present.
+		withComponents := *doc
+		withComponents.Components = &openapi3.Components{}
+		doc = &withComponents
+	}
+	doc2, err := openapi2conv.FromV3(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert OpenAPI 3 document to Swagger 2.0: %w", err)
+	}
+	return doc2, nil
+}
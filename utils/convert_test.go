@@ -0,0 +1,114 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const swagger2Doc = `
+swagger: "2.0"
+info:
+  title: Swagger Test API
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      produces:
+        - application/json
+      responses:
+        200:
+          description: OK
+          schema:
+            type: array
+            items:
+              $ref: '#/definitions/Pet'
+definitions:
+  Pet:
+    type: object
+    properties:
+      name:
+        type: string
+`
+
+func TestIsSwagger2(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected bool
+	}{
+		{
+			name:     "swagger yaml",
+			data:     swagger2Doc,
+			expected: true,
+		},
+		{
+			name:     "swagger json",
+			data:     `{"swagger": "2.0", "info": {"title": "t", "version": "1"}}`,
+			expected: true,
+		},
+		{
+			name:     "unquoted swagger version",
+			data:     "swagger: 2.0\ninfo:\n  title: t\n  version: '1'\n",
+			expected: true,
+		},
+		{
+			name:     "openapi 3",
+			data:     "openapi: 3.0.0\ninfo:\n  title: t\n  version: '1'\n",
+			expected: false,
+		},
+		{
+			name:     "invalid document",
+			data:     "swagger: [",
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, utils.IsSwagger2([]byte(tc.data)))
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestConvertSwagger2() {
+	doc, err := utils.ConvertSwagger2([]byte(swagger2Doc), nil, nil)
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), doc)
+	assert.Contains(suite.T(), doc.Components.Schemas, "Pet")
+	assert.NotNil(suite.T(), doc.Paths.Value("/pets"))
+
+	// The converted document goes through the same pipeline as an OpenAPI 3 one.
+	output, err := utils.SplitByPath(doc, map[string][]string{"/pets": {"get"}})
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output.Components.Schemas, "Pet")
+
+	doc2, err := utils.ToSwagger2(output)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "2.0", doc2.Swagger)
+	assert.Contains(suite.T(), doc2.Definitions, "Pet")
+	assert.Contains(suite.T(), doc2.Paths, "/pets")
+}
+
+func (suite *UtilsTestSuite) TestConvertSwagger2InvalidDocument() {
+	_, err := utils.ConvertSwagger2([]byte("swagger: ["), nil, nil)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *UtilsTestSuite) TestToSwagger2Webhooks() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	_, err := utils.ToSwagger2(doc)
+	assert.ErrorIs(suite.T(), err, utils.ErrUnconvertibleDocument)
+
+	// Without webhooks, the components path items are still 3.1-only
+	delete(doc.Extensions, "webhooks")
+	_, err = utils.ToSwagger2(doc)
+	assert.ErrorIs(suite.T(), err, utils.ErrUnconvertibleDocument)
+	assert.ErrorContains(suite.T(), err, "pathItems")
+}
+
+func (suite *UtilsTestSuite) TestToSwagger2NilDocument() {
+	_, err := utils.ToSwagger2(nil)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
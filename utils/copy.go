@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// deepCopy returns a copy of v that shares no pointer, map or slice with it,
+// so the copy can be modified without touching v.
+// Pointers and maps shared within v are copied once and stay shared within
+// the copy, which keeps the shape of referenced components and recursive
+// schemas. Only exported fields are copied, plus the entries of the map-like
+// openapi3 types (Paths, Responses and Callback). The loader state kept in
+// unexported fields, such as the location of external references that Bundle
+// relies on, is not.
+func deepCopy[T any](v T) T {
+	return copyValue(&deepCopier{copies: make(map[copyKey]reflect.Value)}, v)
+}
+
+func copyValue[T any](c *deepCopier, v T) T {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+	c.copy(dst, src)
+	return dst.Interface().(T)
+}
+
+type copyKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+type deepCopier struct {
+	copies map[copyKey]reflect.Value
+}
+
+// copy copies src into dst, both of the same type. dst must be settable and
+// src addressable.
+func (c *deepCopier) copy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		key := copyKey{typ: src.Type(), ptr: src.Pointer()}
+		if copied, ok := c.copies[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.New(src.Type().Elem())
+		c.copies[key] = copied
+		c.copy(copied.Elem(), src.Elem())
+		dst.Set(copied)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.New(src.Elem().Type()).Elem()
+		c.copy(copied, addressable(src.Elem()))
+		dst.Set(copied)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		key := copyKey{typ: src.Type(), ptr: src.Pointer()}
+		if copied, ok := c.copies[key]; ok {
+			dst.Set(copied)
+			return
+		}
+		copied := reflect.MakeMapWithSize(src.Type(), src.Len())
+		c.copies[key] = copied
+		iter := src.MapRange()
+		for iter.Next() {
+			mapKey := reflect.New(src.Type().Key()).Elem()
+			c.copy(mapKey, addressable(iter.Key()))
+			mapValue := reflect.New(src.Type().Elem()).Elem()
+			c.copy(mapValue, addressable(iter.Value()))
+			copied.SetMapIndex(mapKey, mapValue)
+		}
+		dst.Set(copied)
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		copied := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			c.copy(copied.Index(i), src.Index(i))
+		}
+		dst.Set(copied)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			c.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				c.copy(dst.Field(i), src.Field(i))
+			}
+		}
+		c.copyEntries(dst, src)
+	default:
+		dst.Set(src)
+	}
+}
+
+// addressable returns an addressable copy of v.
+func addressable(v reflect.Value) reflect.Value {
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	return copied
+}
+
+// copyEntries copies the entries the map-like openapi3 types keep in an
+// unexported map, through their Map and Set methods.
+func (c *deepCopier) copyEntries(dst, src reflect.Value) {
+	switch src := src.Addr().Interface().(type) {
+	case *openapi3.Paths:
+		dst := dst.Addr().Interface().(*openapi3.Paths)
+		for path, pathItem := range src.Map() {
+			dst.Set(path, copyValue(c, pathItem))
+		}
+	case *openapi3.Responses:
+		dst := dst.Addr().Interface().(*openapi3.Responses)
+		for status, response := range src.Map() {
+			dst.Set(status, copyValue(c, response))
+		}
+	case *openapi3.Callback:
+		dst := dst.Addr().Interface().(*openapi3.Callback)
+		for expression, pathItem := range src.Map() {
+			dst.Set(expression, copyValue(c, pathItem))
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// DropDeprecated returns a document without the deprecated operations,
+// parameters and schema properties (the properties are removed from the
+// required lists as well), and without the components only they referenced.
+// Components and tags that no path referenced in the first place are kept.
+// The source document is never modified.
+func DropDeprecated(doc *openapi3.T) (*openapi3.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	// The copy is modified in place and keeps the shape of doc, so a property
+	// removed from a component schema is removed everywhere it is referenced
+	modified := deepCopy(doc)
+
+	// The operations are counted before they are dropped. The OpenAPI 3.1 path
+	// items are raw values: the webhooks are counted with the components path
+	// items they reference
+	paths := make(map[string]int)
+	for path, pathItem := range modified.Paths.Map() {
+		paths[path] = countOperations(pathItem.Operations())
+	}
+	webhookOperationsBefore := webhookOperations(modified)
+
+	d := &deprecationDropper{components: modified.Components}
+	walker := &Walker{Enter: d.enter, Leave: d.leave, Refs: FollowRefs}
+	if err := walker.Walk(modified); err != nil {
+		return nil, err
+	}
+
+	operations, kept := 0, 0
+	for path, count := range paths {
+		remaining := countOperations(modified.Paths.Value(path).Operations())
+		operations += count
+		kept += remaining
+		if count > 0 && remaining == 0 {
+			modified.Paths.Delete(path)
+		}
+	}
+	items := webhooks(modified)
+	webhookOperationsAfter := webhookOperations(modified)
+	for name, webhookOperations := range webhookOperationsBefore {
+		count, remaining := countOperations(webhookOperations), countOperations(webhookOperationsAfter[name])
+		operations += count
+		kept += remaining
+		if count > 0 && remaining == 0 {
+			delete(items, name)
+		}
+	}
+	if operations > 0 && kept == 0 {
+		return nil, fmt.Errorf("%w: every operation is deprecated", ErrOpenAPIOperationNotFound)
+	}
+	if components := modified.Components; components != nil {
+		for name, param := range components.Parameters {
+			if isDeprecatedParameter(param) {
+				delete(components.Parameters, name)
+			}
+		}
+	}
+	return pruneDocument(doc, modified, allOperationTargets(modified)), nil
+}
+
+// deprecationDropper removes the deprecated parts of a document in place, as
+// the callbacks of a Walker following the references.
+type deprecationDropper struct {
+	components *openapi3.Components
+	// pathItems holds the path items being walked, the innermost last.
+	pathItems []*openapi3.PathItem
+}
+
+func (d *deprecationDropper) enter(node *Node) error {
+	switch value := node.Value.(type) {
+	case *openapi3.PathItem:
+		d.pathItems = append(d.pathItems, value)
+		for method, operation := range value.Operations() {
+			if operation != nil && operation.Deprecated {
+				value.SetOperation(method, nil)
+				d.inline()
+			}
+		}
+		value.Parameters = d.dropParameters(value.Parameters)
+	case *openapi3.Operation:
+		value.Parameters = d.dropParameters(value.Parameters)
+	case *openapi3.Schema:
+		dropProperties(value)
+		for key, keyword := range value.Extensions {
+			if !isExtensionKey(key) {
+				// An OpenAPI 3.1 keyword (e.g. $defs), its schemas are raw values
+				walkRawObjects(key, keyword, dropRawProperties)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *deprecationDropper) leave(node *Node) error {
+	if _, ok := node.Value.(*openapi3.PathItem); ok {
+		d.pathItems = d.pathItems[:len(d.pathItems)-1]
+	}
+	return nil
+}
+
+// inline drops the reference of the path item being walked: the referenced
+// path item would bring back the deprecated parts, so the resolved one is
+// written instead.
+func (d *deprecationDropper) inline() {
+	d.pathItems[len(d.pathItems)-1].Ref = ""
+}
+
+// dropParameters returns the parameters that are not deprecated.
+func (d *deprecationDropper) dropParameters(params openapi3.Parameters) openapi3.Parameters {
+	if len(params) == 0 {
+		return params
+	}
+	kept := slices.DeleteFunc(slices.Clone(params), d.isDeprecatedParameter)
+	if len(kept) == len(params) {
+		return params
+	}
+	d.inline()
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func isDeprecatedParameter(param *openapi3.ParameterRef) bool {
+	return param != nil && param.Value != nil && param.Value.Deprecated
+}
+
+// isDeprecatedParameter reports whether the parameter, or the component
+// parameter it references, is deprecated. The references of the decoded
+// OpenAPI 3.1 path items are not resolved.
+func (d *deprecationDropper) isDeprecatedParameter(param *openapi3.ParameterRef) bool {
+	if param != nil && param.Value == nil && d.components != nil {
+		if typ, name := ExtractReferenceName(param.Ref); typ == "parameters" {
+			param = d.components.Parameters[name]
+		}
+	}
+	return isDeprecatedParameter(param)
+}
+
+// dropProperties removes the deprecated properties of the schema, and from its
+// required list.
+func dropProperties(schema *openapi3.Schema) {
+	for name, property := range schema.Properties {
+		if property != nil && property.Value != nil && property.Value.Deprecated {
+			delete(schema.Properties, name)
+			schema.Required = slices.DeleteFunc(schema.Required, func(required string) bool { return required == name })
+		}
+	}
+	if len(schema.Required) == 0 {
+		schema.Required = nil
+	}
+}
+
+// dropRawProperties is dropProperties for a raw OpenAPI 3.1 schema.
+func dropRawProperties(schema map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	for name, property := range properties {
+		if property, ok := property.(map[string]any); ok && property["deprecated"] == true {
+			delete(properties, name)
+			if required, ok := schema["required"].([]any); ok {
+				schema["required"] = slices.DeleteFunc(required, func(required any) bool { return required == name })
+			}
+		}
+	}
+	if required, ok := schema["required"].([]any); ok && len(required) == 0 {
+		delete(schema, "required")
+	}
+}
+
+func countOperations(operations map[string]*openapi3.Operation) int {
+	count := 0
+	for _, operation := range operations {
+		if operation != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,119 @@
+package utils_test
+
+import (
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *UtilsTestSuite) TestDropDeprecated() {
+	doc := suite.loadDoc(deprecatedFile)
+	output, err := utils.DropDeprecated(doc)
+	assert.NoError(suite.T(), err)
+
+	// Operations
+	assert.Nil(suite.T(), output.Paths.Value("/legacy"), "A path without operations left is removed")
+	users := output.Paths.Value("/users")
+	assert.NotNil(suite.T(), users.Get)
+	assert.Nil(suite.T(), users.Post)
+	assert.Equal(suite.T(), []string{"users", "unused"}, tagNames(output.Tags), "Tags never used are kept")
+
+	// Parameters
+	assert.Empty(suite.T(), users.Parameters)
+	assert.Len(suite.T(), users.Get.Parameters, 1)
+	assert.Equal(suite.T(), "page", users.Get.Parameters[0].Value.Name)
+	assert.NotContains(suite.T(), output.Components.Parameters, "Tenant")
+
+	// Properties
+	user := output.Components.Schemas["User"].Value
+	assert.Contains(suite.T(), user.Properties, "id")
+	assert.Contains(suite.T(), user.Properties, "manager")
+	assert.NotContains(suite.T(), user.Properties, "nickname")
+	assert.NotContains(suite.T(), user.Properties, "address", "A property referencing a deprecated schema is deprecated")
+	assert.Equal(suite.T(), []string{"id"}, user.Required)
+
+	// Components left unused are removed, the ones never used are kept
+	for _, name := range []string{"Address", "Street", "LegacyUser", "Offset", "Tenant"} {
+		assert.NotContains(suite.T(), output.Components.Schemas, name)
+	}
+	unreferenced := output.Components.Schemas["Unreferenced"]
+	assert.NotNil(suite.T(), unreferenced)
+	assert.NotContains(suite.T(), unreferenced.Value.Properties, "old")
+	assert.Nil(suite.T(), unreferenced.Value.Required)
+
+	// The source document is unchanged
+	assert.NotNil(suite.T(), doc.Paths.Value("/legacy"))
+	assert.NotNil(suite.T(), doc.Paths.Value("/users").Post)
+	assert.Len(suite.T(), doc.Paths.Value("/users").Get.Parameters, 2)
+	assert.Contains(suite.T(), doc.Components.Schemas["User"].Value.Properties, "nickname")
+	assert.Equal(suite.T(), []string{"id", "nickname"}, doc.Components.Schemas["User"].Value.Required)
+}
+
+func (suite *UtilsTestSuite) TestDropDeprecated_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	webhook := webhookItems(doc)["user.created"].(map[string]any)
+	post := webhook["post"].(map[string]any)
+	post["deprecated"] = true
+
+	output, err := utils.DropDeprecated(doc)
+	assert.NoError(suite.T(), err)
+	assert.NotContains(suite.T(), webhookItems(output), "user.created")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.Parameters, "Signature")
+	assert.Contains(suite.T(), webhookItems(doc), "user.created", "The source document is unchanged")
+}
+
+func (suite *UtilsTestSuite) TestDropDeprecated_WebhookSchemas() {
+	doc := suite.loadDoc(deprecatedWebhooksFile)
+	output, err := utils.DropDeprecated(doc)
+	assert.NoError(suite.T(), err)
+
+	m := suite.marshalOpenAPI31(output)
+	paid := lookup(m, "webhooks", "invoice.paid").(map[string]any)
+	assert.Nil(suite.T(), paid["parameters"], "The deprecated and the referenced deprecated parameters are removed")
+	schema := lookup(paid, "post", "requestBody", "content", "application/json", "schema").(map[string]any)
+	assert.NotContains(suite.T(), schema["properties"], "legacyAmount")
+	assert.Equal(suite.T(), []any{"id", "amount"}, schema["required"])
+	assert.EqualValues(suite.T(), 0, lookup(schema, "properties", "amount", "exclusiveMinimum"), "3.1 keywords survive")
+	assert.Equal(suite.T(), "#/components/schemas/Reason", lookup(schema, "properties", "reason", "$ref"))
+
+	// The referenced path item is cleaned where it is defined
+	assert.Equal(suite.T(), "#/components/pathItems/InvoiceVoided", lookup(m, "webhooks", "invoice.voided", "$ref"))
+	voided := lookup(m, "components", "pathItems", "InvoiceVoided").(map[string]any)
+	assert.Nil(suite.T(), voided["post"])
+	assert.Nil(suite.T(), lookup(voided, "put", "parameters"))
+
+	reason := output.Components.Schemas["Reason"].Value
+	assert.NotContains(suite.T(), reason.Properties, "text")
+	assert.Equal(suite.T(), []string{"code"}, reason.Required)
+	assert.NotContains(suite.T(), output.Components.Parameters, "Signature")
+
+	// OpenAPI 3.1 $defs
+	code := lookup(m, "components", "schemas", "Reason", "$defs", "Code").(map[string]any)
+	assert.NotContains(suite.T(), code["properties"], "legacyValue")
+	assert.Equal(suite.T(), []any{"value"}, code["required"])
+}
+
+func (suite *UtilsTestSuite) TestDropDeprecated_ComponentCallback() {
+	doc := suite.loadDoc(deprecatedWebhooksFile)
+	output, err := utils.DropDeprecated(doc)
+	assert.NoError(suite.T(), err)
+
+	// Referenced from a webhook, where the reference is not resolved
+	notice := output.Components.Callbacks["PaymentNotice"]
+	if assert.NotNil(suite.T(), notice) {
+		pathItem := notice.Value.Value("{$request.body#/callbackUrl}")
+		assert.Nil(suite.T(), pathItem.Post)
+		assert.NotNil(suite.T(), pathItem.Put)
+	}
+	assert.NotNil(suite.T(), doc.Components.Callbacks["PaymentNotice"].Value.Value("{$request.body#/callbackUrl}").Post, "The source document is unchanged")
+}
+
+func (suite *UtilsTestSuite) TestDropDeprecated_AllDeprecated() {
+	doc := suite.loadDoc(deprecatedFile)
+	doc.Paths.Value("/users").Get.Deprecated = true
+	_, err := utils.DropDeprecated(doc)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIOperationNotFound)
+
+	_, err = utils.DropDeprecated(nil)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
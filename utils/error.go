@@ -3,6 +3,17 @@ package utils
 import "errors"
 
 var (
-	ErrOpenAPINotFound     = errors.New("OpenAPI document not found")
-	ErrOpenAPIPathNotFound = errors.New("OpenAPI path not found")
+	ErrOpenAPINotFound          = errors.New("OpenAPI document not found")
+	ErrOpenAPIPathNotFound      = errors.New("OpenAPI path not found")
+	ErrUnknownFormat            = errors.New("unknown document format")
+	ErrUnconvertibleDocument    = errors.New("document cannot be converted")
+	ErrInvalidPathPattern       = errors.New("invalid path pattern")
+	ErrOpenAPITagNotFound       = errors.New("OpenAPI tag not found")
+	ErrOpenAPIOperationNotFound = errors.New("OpenAPI operation not found")
+	ErrUnknownExplodeUnit       = errors.New("unknown explode unit")
+	ErrInvalidExtensionMatcher  = errors.New("invalid extension matcher")
+	ErrInvalidExtensionPattern  = errors.New("invalid extension pattern")
+	ErrInvalidExtensionRule     = errors.New("invalid extension rule")
+	ErrInvalidExtensionMapping  = errors.New("invalid extension mapping")
+	ErrInvalidExtensionValue    = errors.New("invalid extension value")
 )
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// The units Explode splits a document into.
+const (
+	ExplodeByPath      = "path"
+	ExplodeByTag       = "tag"
+	ExplodeByOperation = "operation"
+)
+
+// ExplodedDocument is one of the self-contained documents returned by Explode.
+// The fields describing the unit are empty when they do not apply, e.g. Method
+// when exploding by path. Webhook is set instead of Path for the OpenAPI 3.1
+// webhooks.
+type ExplodedDocument struct {
+	Path        string
+	Webhook     string
+	Method      string
+	Tag         string
+	OperationID string
+	Doc         *openapi3.T
+}
+
+// Name returns a file name friendly name of the unit: the tag, the operationId
+// (or the method and path when there is none) or the path. Webhook names are
+// prefixed with "webhook_".
+func (d ExplodedDocument) Name() string {
+	switch {
+	case d.OperationID != "":
+		return SafeFileName(d.OperationID)
+	case d.Method != "":
+		return SafeFileName(strings.ToLower(d.Method)) + "_" + d.location()
+	case d.Path != "" || d.Webhook != "":
+		return d.location()
+	default:
+		return SafeFileName(d.Tag)
+	}
+}
+
+// location returns the file name friendly path or webhook name of the unit.
+func (d ExplodedDocument) location() string {
+	if d.Webhook != "" {
+		return "webhook_" + SafeFileName(d.Webhook)
+	}
+	return SafeFileName(d.Path)
+}
+
+// less orders the documents by path, then by webhook name, then by method.
+func (d ExplodedDocument) less(other ExplodedDocument) bool {
+	if (d.Webhook != "") != (other.Webhook != "") {
+		return d.Webhook == ""
+	}
+	if d.Path != other.Path {
+		return d.Path < other.Path
+	}
+	if d.Webhook != other.Webhook {
+		return d.Webhook < other.Webhook
+	}
+	return d.Method < other.Method
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SafeFileName turns s into a single file name component, e.g. "/users/{id}"
+// into "users_id". It returns "root" for the path "/" and "_" when nothing is
+// left.
+func SafeFileName(s string) string {
+	if s == "/" {
+		return "root"
+	}
+	name := strings.Trim(unsafeFileNameChars.ReplaceAllString(s, "_"), "_.")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// Explode splits the document into one self-contained document per path, tag
+// or operation (see ExplodeByPath, ExplodeByTag and ExplodeByOperation), each
+// with the components it references.
+// The OpenAPI 3.1 webhooks are exploded like the paths, each document holds
+// only its own path or webhook. Operations without tags are left out when
+// exploding by tag.
+// The documents are sorted by path and method (paths before webhooks), or by
+// tag in the order of the top-level tags array.
+func Explode(doc *openapi3.T, by string) ([]ExplodedDocument, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	var (
+		exploded []ExplodedDocument
+		err      error
+	)
+	switch by {
+	case ExplodeByPath:
+		exploded, err = explodeByPath(doc)
+	case ExplodeByTag:
+		exploded, err = explodeByTag(doc)
+	case ExplodeByOperation:
+		exploded, err = explodeByOperation(doc)
+	default:
+		return nil, fmt.Errorf("%w: '%s' (expected '%s', '%s' or '%s')", ErrUnknownExplodeUnit, by, ExplodeByPath, ExplodeByTag, ExplodeByOperation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(exploded) == 0 {
+		return nil, ErrOpenAPIPathNotFound
+	}
+	return exploded, nil
+}
+
+func explodeByPath(doc *openapi3.T) ([]ExplodedDocument, error) {
+	var exploded []ExplodedDocument
+	for path := range doc.Paths.Map() {
+		splitDoc, err := splitByPath(doc, map[string][]string{ExactPathPattern(path): {}}, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split path '%s': %w", path, err)
+		}
+		exploded = append(exploded, ExplodedDocument{Path: path, Doc: splitDoc})
+	}
+	for name := range webhookOperations(doc) {
+		splitDoc, err := splitByPath(doc, map[string][]string{WebhookTargetPrefix + ExactPathPattern(name): {}}, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split webhook '%s': %w", name, err)
+		}
+		exploded = append(exploded, ExplodedDocument{Webhook: name, Doc: splitDoc})
+	}
+	sort.Slice(exploded, func(i, j int) bool { return exploded[i].less(exploded[j]) })
+	return exploded, nil
+}
+
+func explodeByTag(doc *openapi3.T) ([]ExplodedDocument, error) {
+	// The tags defined at the top level come first, in their order,
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range doc.Tags {
+		if tag != nil && !seen[tag.Name] {
+			seen[tag.Name] = true
+			tags = append(tags, tag.Name)
+		}
+	}
+	// followed by the tags only used by operations, sorted
+	used := usedTags(doc)
+	var undefined []string
+	for tag := range used {
+		if !seen[tag] {
+			undefined = append(undefined, tag)
+		}
+	}
+	sort.Strings(undefined)
+	tags = append(tags, undefined...)
+
+	var exploded []ExplodedDocument
+	for _, tag := range tags {
+		if !used[tag] {
+			continue
+		}
+		splitDoc, err := SplitByTag(doc, []string{tag})
+		if err != nil {
+			return nil, fmt.Errorf("failed to split tag '%s': %w", tag, err)
+		}
+		exploded = append(exploded, ExplodedDocument{Tag: tag, Doc: splitDoc})
+	}
+	return exploded, nil
+}
+
+func explodeByOperation(doc *openapi3.T) ([]ExplodedDocument, error) {
+	var exploded []ExplodedDocument
+	add := func(unit ExplodedDocument, target string, operations map[string]*openapi3.Operation) error {
+		for method, operation := range operations {
+			if operation == nil {
+				continue
+			}
+			splitDoc, err := splitByPath(doc, map[string][]string{target: {method}}, false)
+			if err != nil {
+				return fmt.Errorf("failed to split operation %s '%s': %w", method, unit.Path+unit.Webhook, err)
+			}
+			part := unit
+			part.Method = method
+			if len(operation.Tags) > 0 {
+				part.Tag = operation.Tags[0]
+			}
+			part.OperationID = operation.OperationID
+			part.Doc = splitDoc
+			exploded = append(exploded, part)
+		}
+		return nil
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		if err := add(ExplodedDocument{Path: path}, ExactPathPattern(path), pathItem.Operations()); err != nil {
+			return nil, err
+		}
+	}
+	for name, operations := range webhookOperations(doc) {
+		if err := add(ExplodedDocument{Webhook: name}, WebhookTargetPrefix+ExactPathPattern(name), operations); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(exploded, func(i, j int) bool { return exploded[i].less(exploded[j]) })
+	return exploded, nil
+}
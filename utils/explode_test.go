@@ -0,0 +1,116 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeFileName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "path", input: "/users/{id}/orders", expected: "users_id_orders"},
+		{name: "root path", input: "/", expected: "root"},
+		{name: "operationId", input: "listInvoices", expected: "listInvoices"},
+		{name: "tag with spaces", input: "Billing & Invoices", expected: "Billing_Invoices"},
+		{name: "dots are trimmed", input: "../etc", expected: "etc"},
+		{name: "empty", input: "", expected: "_"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, utils.SafeFileName(tc.input))
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestExplodeByPath() {
+	doc := suite.loadDoc(splitTagsFile)
+	exploded, err := utils.Explode(doc, utils.ExplodeByPath)
+	assert.NoError(suite.T(), err)
+
+	if assert.Len(suite.T(), exploded, 3) {
+		assert.Equal(suite.T(), []string{"/invoices", "/payments", "/users"},
+			[]string{exploded[0].Path, exploded[1].Path, exploded[2].Path})
+		assert.Equal(suite.T(), "invoices", exploded[0].Name())
+		// Every document is self-contained
+		assert.Len(suite.T(), exploded[2].Doc.Paths.Map(), 1)
+		assert.Contains(suite.T(), exploded[2].Doc.Components.Schemas, "User")
+		assert.NotContains(suite.T(), exploded[2].Doc.Components.Schemas, "Invoice")
+	}
+}
+
+func (suite *UtilsTestSuite) TestExplodeByTag() {
+	doc := suite.loadDoc(splitTagsFile)
+	exploded, err := utils.Explode(doc, utils.ExplodeByTag)
+	assert.NoError(suite.T(), err)
+
+	if assert.Len(suite.T(), exploded, 3) {
+		assert.Equal(suite.T(), []string{"billing", "invoices", "users"},
+			[]string{exploded[0].Tag, exploded[1].Tag, exploded[2].Tag}, "Tags keep the document order")
+		billing := exploded[0].Doc
+		assert.NotNil(suite.T(), billing.Paths.Value("/invoices").Get)
+		assert.Nil(suite.T(), billing.Paths.Value("/invoices").Post)
+		assert.NotNil(suite.T(), billing.Paths.Value("/payments"))
+		assert.Equal(suite.T(), "billing", exploded[0].Name())
+	}
+}
+
+func (suite *UtilsTestSuite) TestExplodeByOperation() {
+	doc := suite.loadDoc(splitTagsFile)
+	exploded, err := utils.Explode(doc, utils.ExplodeByOperation)
+	assert.NoError(suite.T(), err)
+
+	if assert.Len(suite.T(), exploded, 4) {
+		first := exploded[0]
+		assert.Equal(suite.T(), "/invoices", first.Path)
+		assert.Equal(suite.T(), "GET", first.Method)
+		assert.Equal(suite.T(), "listInvoices", first.OperationID)
+		assert.Equal(suite.T(), "billing", first.Tag, "The first tag of the operation")
+		assert.Equal(suite.T(), "listInvoices", first.Name())
+		assert.Nil(suite.T(), first.Doc.Paths.Value("/invoices").Post)
+	}
+	assert.Equal(suite.T(), "get_users_id", utils.ExplodedDocument{Path: "/users/{id}", Method: "GET"}.Name())
+}
+
+func (suite *UtilsTestSuite) TestExplode_Webhooks() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	exploded, err := utils.Explode(doc, utils.ExplodeByPath)
+	assert.NoError(suite.T(), err)
+
+	if assert.Len(suite.T(), exploded, 4) {
+		assert.Equal(suite.T(), "/invoices", exploded[0].Path)
+		assert.Equal(suite.T(), []string{"invoice.paid", "invoice.voided", "user.created"},
+			[]string{exploded[1].Webhook, exploded[2].Webhook, exploded[3].Webhook}, "Webhooks follow the paths")
+		assert.Empty(suite.T(), webhookItems(exploded[0].Doc), "Every document holds only its own path or webhook")
+		assert.Equal(suite.T(), "webhook_invoice.paid", exploded[1].Name())
+		assert.Empty(suite.T(), exploded[3].Doc.Paths.Map())
+		assert.Len(suite.T(), webhookItems(exploded[3].Doc), 1)
+		assert.Contains(suite.T(), exploded[3].Doc.Components.Schemas, "User")
+	}
+
+	exploded, err = utils.Explode(doc, utils.ExplodeByOperation)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), exploded, 5) {
+		voided := exploded[3]
+		assert.Equal(suite.T(), "invoice.voided", voided.Webhook)
+		assert.Equal(suite.T(), "PUT", voided.Method)
+		assert.Equal(suite.T(), "invoiceVoidReason", voided.Name())
+		webhook, _ := webhookItems(voided.Doc)["invoice.voided"].(map[string]any)
+		assert.Nil(suite.T(), webhook["post"])
+	}
+	assert.Equal(suite.T(), "post_webhook_invoice.paid", utils.ExplodedDocument{Webhook: "invoice.paid", Method: "POST"}.Name())
+}
+
+func (suite *UtilsTestSuite) TestExplodeErrors() {
+	doc := suite.loadDoc(splitTagsFile)
+	_, err := utils.Explode(doc, "schema")
+	assert.ErrorIs(suite.T(), err, utils.ErrUnknownExplodeUnit)
+
+	_, err = utils.Explode(nil, utils.ExplodeByPath)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExtensionMatcher matches operations by the value of one of their extensions.
+type ExtensionMatcher struct {
+	// Name is the extension name, e.g. "x-audience".
+	Name string
+	// Values are the accepted values, any value is accepted when empty.
+	Values []string
+}
+
+// ParseExtensionMatcher parses an extension matcher expression:
+//   - "x-internal" matches the operations carrying the extension
+//   - "x-stability=beta" matches the operations where it equals the value
+//   - "x-audience=public|partner" matches the operations where it equals one
+//     of the values
+func ParseExtensionMatcher(expr string) (ExtensionMatcher, error) {
+	name, values, hasValues := strings.Cut(expr, "=")
+	name = strings.TrimSpace(name)
+	if !isExtensionKey(name) {
+		return ExtensionMatcher{}, fmt.Errorf("%w: '%s' (expected x-name, x-name=value or x-name=value1|value2)", ErrInvalidExtensionMatcher, expr)
+	}
+	matcher := ExtensionMatcher{Name: name}
+	if hasValues {
+		for _, value := range strings.Split(values, "|") {
+			matcher.Values = append(matcher.Values, strings.TrimSpace(value))
+		}
+	}
+	return matcher, nil
+}
+
+// String returns the matcher as an expression accepted by ParseExtensionMatcher.
+func (m ExtensionMatcher) String() string {
+	if len(m.Values) == 0 {
+		return m.Name
+	}
+	return m.Name + "=" + strings.Join(m.Values, "|")
+}
+
+// Match reports whether the extensions hold the extension with one of the
+// accepted values. Scalars are compared by their string form (e.g. true is
+// "true") and a list matches when one of its items does.
+func (m ExtensionMatcher) Match(extensions map[string]any) bool {
+	value, ok := extensions[m.Name]
+	if !ok {
+		return false
+	}
+	if len(m.Values) == 0 {
+		return true
+	}
+	if items, ok := value.([]any); ok {
+		for _, item := range items {
+			if m.matchValue(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return m.matchValue(value)
+}
+
+func (m ExtensionMatcher) matchValue(value any) bool {
+	switch value.(type) {
+	case map[string]any, []any, nil:
+		return false
+	}
+	s := fmt.Sprint(value)
+	for _, accepted := range m.Values {
+		if s == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterOperationsByExtension returns a document with only the operations, of
+// the paths and of the webhooks, matching every keep matcher and none of the
+// drop matchers. An operation without the extension inherits it from its path
+// item, e.g. "x-internal: true" on a path marks all of its operations.
+// Components and tags that are no longer referenced are removed as with
+// ExcludePaths. The source document is never modified.
+func FilterOperationsByExtension(doc *openapi3.T, keep []ExtensionMatcher, drop []ExtensionMatcher) (*openapi3.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	kept := func(extensions map[string]any) bool {
+		for _, matcher := range keep {
+			if !matcher.Match(extensions) {
+				return false
+			}
+		}
+		for _, matcher := range drop {
+			if matcher.Match(extensions) {
+				return false
+			}
+		}
+		return true
+	}
+
+	targets := map[string][]string{}
+	remaining := 0
+	filter := func(target string, pathExtensions map[string]any, operations map[string]*openapi3.Operation) {
+		for method, operation := range operations {
+			if operation == nil {
+				continue
+			}
+			extensions := make(map[string]any, len(pathExtensions)+len(operation.Extensions))
+			for name, value := range pathExtensions {
+				extensions[name] = value
+			}
+			for name, value := range operation.Extensions {
+				extensions[name] = value
+			}
+			if kept(extensions) {
+				remaining++
+				continue
+			}
+			targets[target] = append(targets[target], method)
+		}
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		filter(ExactPathPattern(path), pathItem.Extensions, pathItem.Operations())
+	}
+	for name, raw := range webhooks(doc) {
+		if pathItem := webhookPathItem(doc, raw); pathItem != nil {
+			filter(WebhookTargetPrefix+ExactPathPattern(name), pathItem.Extensions, pathItem.Operations())
+		}
+	}
+	if remaining == 0 {
+		return nil, fmt.Errorf("%w: no operation matches the extension filters", ErrOpenAPIOperationNotFound)
+	}
+	return ExcludePaths(doc, targets)
+}
@@ -0,0 +1,128 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtensionMatcher(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected utils.ExtensionMatcher
+		err      bool
+	}{
+		{expr: "x-internal", expected: utils.ExtensionMatcher{Name: "x-internal"}},
+		{expr: "x-stability=beta", expected: utils.ExtensionMatcher{Name: "x-stability", Values: []string{"beta"}}},
+		{expr: "x-audience = public | partner", expected: utils.ExtensionMatcher{Name: "x-audience", Values: []string{"public", "partner"}}},
+		{expr: "x-note=", expected: utils.ExtensionMatcher{Name: "x-note", Values: []string{""}}},
+		{expr: "audience=public", err: true},
+		{expr: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			matcher, err := utils.ParseExtensionMatcher(tt.expr)
+			if tt.err {
+				assert.ErrorIs(t, err, utils.ErrInvalidExtensionMatcher)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, matcher)
+		})
+	}
+}
+
+func TestExtensionMatcherMatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		matcher    utils.ExtensionMatcher
+		extensions map[string]any
+		expected   bool
+	}{
+		{name: "presence", matcher: utils.ExtensionMatcher{Name: "x-internal"}, extensions: map[string]any{"x-internal": false}, expected: true},
+		{name: "absent", matcher: utils.ExtensionMatcher{Name: "x-internal"}, extensions: map[string]any{}, expected: false},
+		{name: "equal", matcher: utils.ExtensionMatcher{Name: "x-stability", Values: []string{"beta"}}, extensions: map[string]any{"x-stability": "beta"}, expected: true},
+		{name: "not equal", matcher: utils.ExtensionMatcher{Name: "x-stability", Values: []string{"beta"}}, extensions: map[string]any{"x-stability": "stable"}, expected: false},
+		{name: "boolean", matcher: utils.ExtensionMatcher{Name: "x-internal", Values: []string{"true"}}, extensions: map[string]any{"x-internal": true}, expected: true},
+		{name: "number", matcher: utils.ExtensionMatcher{Name: "x-version", Values: []string{"2"}}, extensions: map[string]any{"x-version": float64(2)}, expected: true},
+		{name: "set membership", matcher: utils.ExtensionMatcher{Name: "x-audience", Values: []string{"public", "partner"}}, extensions: map[string]any{"x-audience": "partner"}, expected: true},
+		{name: "list item", matcher: utils.ExtensionMatcher{Name: "x-audience", Values: []string{"partner"}}, extensions: map[string]any{"x-audience": []any{"public", "partner"}}, expected: true},
+		{name: "object", matcher: utils.ExtensionMatcher{Name: "x-meta", Values: []string{"map[]"}}, extensions: map[string]any{"x-meta": map[string]any{}}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.matcher.Match(tt.extensions))
+		})
+	}
+}
+
+// operationIDs returns the operationIds of the document paths.
+func operationIDs(doc *openapi3.T) []string {
+	var ids []string
+	for _, pathItem := range doc.Paths.Map() {
+		for _, operation := range pathItem.Operations() {
+			ids = append(ids, operation.OperationID)
+		}
+	}
+	return ids
+}
+
+func (suite *UtilsTestSuite) TestFilterOperationsByExtension_Keep() {
+	doc := suite.loadDoc(filterExtensionsFile)
+	output, err := utils.FilterOperationsByExtension(doc, []utils.ExtensionMatcher{
+		{Name: "x-audience", Values: []string{"public"}},
+	}, []utils.ExtensionMatcher{
+		{Name: "x-internal", Values: []string{"true"}},
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.ElementsMatch(suite.T(), []string{"listInvoices", "createInvoice"}, operationIDs(output))
+	assert.Contains(suite.T(), output.Components.Schemas, "Invoice")
+	assert.NotContains(suite.T(), output.Components.Schemas, "Partner")
+	assert.NotContains(suite.T(), output.Components.Schemas, "AdminStats", "The path-level x-internal should drop adminStats")
+	assert.Contains(suite.T(), output.Components.Schemas, "Shared", "Components no operation referenced are kept")
+	assert.Equal(suite.T(), []string{"invoices"}, tagNames(output.Tags))
+
+	// The markers are only read, the source document is unchanged
+	assert.Len(suite.T(), doc.Paths.Map(), 4)
+	assert.Equal(suite.T(), "public", doc.Paths.Value("/invoices").Get.Extensions["x-audience"])
+}
+
+func (suite *UtilsTestSuite) TestFilterOperationsByExtension_Drop() {
+	doc := suite.loadDoc(filterExtensionsFile)
+	output, err := utils.FilterOperationsByExtension(doc, nil, []utils.ExtensionMatcher{
+		{Name: "x-stability", Values: []string{"beta"}},
+		{Name: "x-internal", Values: []string{"true"}},
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.ElementsMatch(suite.T(), []string{"listInvoices", "listPartners", "health"}, operationIDs(output))
+	assert.Nil(suite.T(), output.Paths.Value("/invoices").Post)
+	assert.Nil(suite.T(), output.Paths.Value("/admin/stats"))
+	assert.Equal(suite.T(), []string{"invoices", "partners"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestFilterOperationsByExtension_Presence() {
+	doc := suite.loadDoc(filterExtensionsFile)
+	output, err := utils.FilterOperationsByExtension(doc, nil, []utils.ExtensionMatcher{{Name: "x-internal"}})
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []string{"listInvoices", "createInvoice", "listPartners"}, operationIDs(output))
+}
+
+func (suite *UtilsTestSuite) TestFilterOperationsByExtension_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.FilterOperationsByExtension(doc, nil, []utils.ExtensionMatcher{{Name: "x-internal"}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), webhookItems(output), 3, "Webhooks without the marker are kept")
+
+	output, err = utils.FilterOperationsByExtension(doc, []utils.ExtensionMatcher{{Name: "x-internal"}}, nil)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIOperationNotFound)
+	assert.Nil(suite.T(), output)
+}
+
+func (suite *UtilsTestSuite) TestFilterOperationsByExtensionNilDocument() {
+	_, err := utils.FilterOperationsByExtension(nil, nil, nil)
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
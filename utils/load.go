@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDocument parses a raw OpenAPI 3.0, OpenAPI 3.1 or Swagger 2.0 document
+// (JSON or YAML) into the openapi3 model.
+// Swagger 2.0 documents are converted to OpenAPI 3 and OpenAPI 3.1 documents
+// are normalized with NormalizeOpenAPI31 first.
+// The location is used to resolve relative external references and may be nil.
+func LoadDocument(loader *openapi3.Loader, data []byte, location *url.URL) (*openapi3.T, error) {
+	if loader == nil {
+		loader = openapi3.NewLoader()
+	}
+	if IsSwagger2(data) {
+		return ConvertSwagger2(data, loader, location)
+	}
+	if IsOpenAPI31(data) {
+		normalized, err := NormalizeOpenAPI31(data)
+		if err != nil {
+			return nil, err
+		}
+		data = normalized
+	}
+	if location == nil {
+		return loader.LoadFromData(data)
+	}
+	return loader.LoadFromDataWithPath(data, location)
+}
+
+// documentVersions returns the "openapi" and "swagger" root keys of a raw
+// document (JSON or YAML); both are empty if the document cannot be parsed.
+func documentVersions(data []byte) (openapi, swagger string) {
+	var root struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return "", ""
+	}
+	return root.OpenAPI, root.Swagger
+}
+
+// DetectFormat reports whether a raw document is "json" or "yaml", judging by
+// its first non-blank character.
+func DetectFormat(data []byte) string {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return "json"
+	}
+	return "yaml"
+}
+
+// SyntaxError describes a malformed JSON or YAML document.
+// Column is 0 when the parser does not report it (YAML), and the line of a YAML
+// error is the start of the construct that failed (e.g. an unclosed flow
+// mapping) when yaml.v3 reports one.
+type SyntaxError struct {
+	Format string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s syntax error at line %d, column %d: %s", e.Format, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s syntax error at line %d: %s", e.Format, e.Line, e.Msg)
+}
+
+// yamlErrorLine matches the position yaml.v3 puts in its error messages.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlParserProblems are the messages of the yaml.v3 parser errors. Unlike the
+// scanner errors, their line is 0-based.
+var yamlParserProblems = map[string]struct{}{
+	"did not find expected <stream-start>":   {},
+	"did not find expected <document start>": {},
+	"did not find expected node content":     {},
+	"did not find expected key":              {},
+	"did not find expected '-' indicator":    {},
+	"did not find expected ',' or ']'":       {},
+	"did not find expected ',' or '}'":       {},
+	"found duplicate %YAML directive":        {},
+	"found duplicate %TAG directive":         {},
+	"found incompatible YAML document":       {},
+	"found undefined tag handle":             {},
+}
+
+// CheckSyntax checks that a raw document is well-formed in the given format
+// ("json", "yaml" or "auto") and returns the format it was checked against.
+// With "auto" (or an empty format) the format is detected with DetectFormat.
+// Malformed documents are reported as a *SyntaxError.
+func CheckSyntax(data []byte, format string) (string, error) {
+	switch format {
+	case "", "auto":
+		format = DetectFormat(data)
+	case "json", "yaml":
+	default:
+		return "", fmt.Errorf("%w: '%s' (expected 'auto', 'json' or 'yaml')", ErrUnknownFormat, format)
+	}
+
+	var v any
+	switch format {
+	case "json":
+		err := json.Unmarshal(data, &v)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := offsetToLineColumn(data, syntaxErr.Offset)
+			return format, &SyntaxError{Format: format, Line: line, Column: column, Msg: syntaxErr.Error()}
+		}
+		if err != nil {
+			return format, &SyntaxError{Format: format, Line: 1, Column: 1, Msg: err.Error()}
+		}
+	case "yaml":
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+				line, _ := strconv.Atoi(m[1])
+				if _, ok := yamlParserProblems[m[2]]; ok {
+					line++
+				}
+				return format, &SyntaxError{Format: format, Line: line, Msg: m[2]}
+			}
+			return format, &SyntaxError{Format: format, Line: 1, Msg: err.Error()}
+		}
+	}
+	return format, nil
+}
+
+// offsetToLineColumn converts a byte offset into a 1-based line and column.
+func offsetToLineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - (bytes.LastIndexByte(before, '\n') + 1)
+	if column == 0 {
+		column = 1
+	}
+	return line, column
+}
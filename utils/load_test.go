@@ -0,0 +1,156 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "json object",
+			data:     `{"openapi": "3.0.0"}`,
+			expected: "json",
+		},
+		{
+			name:     "json with leading whitespace",
+			data:     "\n\t  {\"openapi\": \"3.0.0\"}",
+			expected: "json",
+		},
+		{
+			name:     "yaml",
+			data:     "openapi: 3.0.0\n",
+			expected: "yaml",
+		},
+		{
+			name:     "yaml document marker",
+			data:     "---\nopenapi: 3.0.0\n",
+			expected: "yaml",
+		},
+		{
+			name:     "empty",
+			data:     "",
+			expected: "yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, utils.DetectFormat([]byte(tc.data)))
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestLoadDocument() {
+	doc, err := utils.LoadDocument(nil, splitFile, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3.0.0", doc.OpenAPI)
+
+	doc, err = utils.LoadDocument(nil, []byte(`{"openapi": "3.0.3", "info": {"title": "t", "version": "1"}, "paths": {}}`), nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3.0.3", doc.OpenAPI)
+
+	doc, err = utils.LoadDocument(nil, []byte(swagger2Doc), nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3.0.3", doc.OpenAPI, "Swagger 2.0 documents should be converted")
+
+	doc, err = utils.LoadDocument(nil, openAPI31File, nil)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "3.1.0", doc.OpenAPI)
+}
+
+func TestCheckSyntax(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           string
+		format         string
+		expectedFormat string
+		expectedErr    string
+	}{
+		{
+			name:           "auto detects json",
+			data:           `{"openapi": "3.0.0"}`,
+			format:         "auto",
+			expectedFormat: "json",
+		},
+		{
+			name:           "empty format detects yaml",
+			data:           "openapi: 3.0.0\n",
+			format:         "",
+			expectedFormat: "yaml",
+		},
+		{
+			name:           "json syntax error with line and column",
+			data:           "{\n  \"openapi\": \"3.0.0\",\n  \"info\": {,}\n}",
+			format:         "json",
+			expectedFormat: "json",
+			expectedErr:    "json syntax error at line 3, column 12",
+		},
+		{
+			name:           "explicit json rejects yaml",
+			data:           "openapi: 3.0.0\n",
+			format:         "json",
+			expectedFormat: "json",
+			expectedErr:    "json syntax error at line 1, column 1",
+		},
+		{
+			name:           "yaml syntax error with line",
+			data:           "openapi: 3.0.0\ninfo:\n  title: [unclosed\n",
+			format:         "yaml",
+			expectedFormat: "yaml",
+			expectedErr:    "yaml syntax error at line 3: did not find expected ',' or ']'",
+		},
+		{
+			name:           "yaml broken flow mapping",
+			data:           "openapi: 3.0.0\ninfo:\n  title: {a: 1\npaths: {}\n",
+			format:         "yaml",
+			expectedFormat: "yaml",
+			expectedErr:    "yaml syntax error at line 3: did not find expected ',' or '}'",
+		},
+		{
+			name:           "yaml parser error",
+			data:           "openapi: 3.0.0\n- paths\n",
+			format:         "yaml",
+			expectedFormat: "yaml",
+			expectedErr:    "yaml syntax error at line 2: did not find expected key",
+		},
+		{
+			name:           "yaml scanner error",
+			data:           "openapi: 3.0.0\ninfo:\n\ttitle: x\n",
+			format:         "yaml",
+			expectedFormat: "yaml",
+			expectedErr:    "yaml syntax error at line 3: found character that cannot start any token",
+		},
+		{
+			name:           "explicit yaml accepts json",
+			data:           `{"openapi": "3.0.0"}`,
+			format:         "yaml",
+			expectedFormat: "yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			format, err := utils.CheckSyntax([]byte(tc.data), tc.format)
+			assert.Equal(t, tc.expectedFormat, format)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var syntaxErr *utils.SyntaxError
+			assert.ErrorAs(t, err, &syntaxErr)
+			assert.Contains(t, err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestCheckSyntaxUnknownFormat(t *testing.T) {
+	_, err := utils.CheckSyntax([]byte("openapi: 3.0.0\n"), "xml")
+	assert.ErrorIs(t, err, utils.ErrUnknownFormat)
+}
@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v3"
+)
+
+// The openapi3 model of kin-openapi targets OpenAPI 3.0. Most OpenAPI 3.1
+// keywords ($defs, const, examples, prefixItems, webhooks, ...) are unknown to
+// it and are kept verbatim in the Extensions maps, and "type" already accepts
+// a list of types. Only a few 3.1 constructs cannot be parsed at all:
+//   - numeric exclusiveMinimum / exclusiveMaximum (booleans in 3.0)
+//   - boolean schemas (e.g. "items: false")
+//
+// NormalizeOpenAPI31 rewrites those into their 3.0 equivalent before loading,
+// and MarshalOpenAPI31 turns them back into the 3.1 form.
+
+// dataKeywords hold literal data rather than OpenAPI objects, so they are never
+// rewritten.
+var dataKeywords = map[string]struct{}{
+	"example": {},
+	"default": {},
+	"const":   {},
+	"enum":    {},
+}
+
+// schemaMapKeywords hold maps from names to schemas. Their keys are names, not
+// keywords or extensions: a property may well be named "x-name" or "minimum".
+var schemaMapKeywords = map[string]struct{}{
+	"schemas":           {},
+	"properties":        {},
+	"patternProperties": {},
+	"$defs":             {},
+	"dependentSchemas":  {},
+}
+
+// walkRawObjects calls visit for every object of a raw OpenAPI 3.1 value stored
+// under key (e.g. the schemas of "$defs"), before walking the object itself.
+// Literal data (see dataKeywords) and the values of extensions are skipped, and
+// the maps of schemaMapKeywords are walked by value only.
+func walkRawObjects(key string, value any, visit func(object map[string]any)) {
+	if _, ok := dataKeywords[key]; ok {
+		return
+	}
+	switch value := value.(type) {
+	case []any:
+		if key == "examples" {
+			return // schema examples are literal data
+		}
+		for _, item := range value {
+			walkRawObjects("", item, visit)
+		}
+	case map[string]any:
+		if _, ok := schemaMapKeywords[key]; ok {
+			for _, name := range sortedKeys(value) {
+				walkRawObjects("", value[name], visit)
+			}
+			return
+		}
+		visit(value)
+		for _, key := range sortedKeys(value) {
+			if !isExtensionKey(key) {
+				walkRawObjects(key, value[key], visit)
+			}
+		}
+	}
+}
+
+// IsOpenAPI31 reports whether the raw document (JSON or YAML) declares an
+// OpenAPI 3.1 version.
+func IsOpenAPI31(data []byte) bool {
+	openapi, _ := documentVersions(data)
+	return strings.HasPrefix(openapi, "3.1")
+}
+
+// NormalizeOpenAPI31 rewrites the OpenAPI 3.1 constructs the openapi3 loader
+// cannot parse into their OpenAPI 3.0 equivalent:
+//   - "exclusiveMinimum: 5" becomes "minimum: 5" with "exclusiveMinimum: true"
+//     (and likewise for exclusiveMaximum)
+//   - the boolean schema true becomes {} and false becomes {not: {}}
+//
+// The result is a YAML document.
+func NormalizeOpenAPI31(data []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI 3.1 document: %w", err)
+	}
+	normalizeOpenAPI31Node(&root)
+	out, err := yaml.Marshal(&root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI 3.1 document: %w", err)
+	}
+	return out, nil
+}
+
+func normalizeOpenAPI31Node(node *yaml.Node) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			normalizeOpenAPI31Node(child)
+		}
+	case yaml.MappingNode:
+		normalizeExclusiveBound(node, "exclusiveMinimum", "minimum", func(bound, limit float64) bool { return bound >= limit })
+		normalizeExclusiveBound(node, "exclusiveMaximum", "maximum", func(bound, limit float64) bool { return bound <= limit })
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if _, ok := dataKeywords[key]; ok || isExtensionKey(key) {
+				continue
+			}
+			if key == "examples" || key == "links" {
+				continue // schema examples, Example and Link objects hold literal data
+			}
+			switch key {
+			case "items", "not":
+				normalizeBooleanSchema(value)
+			case "allOf", "anyOf", "oneOf":
+				if value.Kind == yaml.SequenceNode {
+					for _, item := range value.Content {
+						normalizeBooleanSchema(item)
+					}
+				}
+			}
+			if _, ok := schemaMapKeywords[key]; ok && value.Kind == yaml.MappingNode {
+				// The keys are names, only the schemas are normalized
+				for j := 1; j < len(value.Content); j += 2 {
+					normalizeBooleanSchema(value.Content[j])
+					normalizeOpenAPI31Node(value.Content[j])
+				}
+				continue
+			}
+			normalizeOpenAPI31Node(value)
+		}
+	}
+}
+
+// normalizeExclusiveBound turns a numeric exclusive bound into the 3.0 form.
+// When the mapping also has an inclusive bound, the stricter of the two wins:
+// tighter reports whether the exclusive bound is at least as strict as the
+// inclusive one.
+func normalizeExclusiveBound(node *yaml.Node, exclusiveKey, inclusiveKey string, tighter func(bound, limit float64) bool) {
+	exclusiveIdx, inclusiveIdx := -1, -1
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		switch node.Content[i].Value {
+		case exclusiveKey:
+			exclusiveIdx = i
+		case inclusiveKey:
+			inclusiveIdx = i
+		}
+	}
+	if exclusiveIdx < 0 {
+		return
+	}
+	bound := node.Content[exclusiveIdx+1]
+	var boundValue float64
+	if bound.Kind != yaml.ScalarNode || (bound.Tag != "!!int" && bound.Tag != "!!float") || bound.Decode(&boundValue) != nil {
+		return // already the 3.0 boolean form
+	}
+
+	if inclusiveIdx >= 0 {
+		var limit float64
+		if err := node.Content[inclusiveIdx+1].Decode(&limit); err == nil && !tighter(boundValue, limit) {
+			// The inclusive bound is stricter, the exclusive one is redundant.
+			node.Content[exclusiveIdx+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+			return
+		}
+		node.Content[inclusiveIdx+1] = bound
+	} else {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: inclusiveKey},
+			bound,
+		)
+	}
+	node.Content[exclusiveIdx+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+}
+
+// normalizeBooleanSchema replaces the boolean schema true with {} and false
+// with {not: {}}.
+func normalizeBooleanSchema(node *yaml.Node) {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+		return
+	}
+	var value bool
+	if err := node.Decode(&value); err != nil {
+		return
+	}
+	*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if !value {
+		node.Content = []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "not"},
+			{Kind: yaml.MappingNode, Tag: "!!map"},
+		}
+	}
+}
+
+// MarshalOpenAPI31 returns the document as a generic tree (maps, slices and
+// scalars) in OpenAPI 3.1 form: exclusive bounds are emitted as numbers,
+// {not: {}} as the boolean schema false and an absent paths object is omitted.
+// It can be encoded with any YAML or JSON encoder.
+func MarshalOpenAPI31(doc *openapi3.T) (any, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	data, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	if paths, ok := out["paths"]; ok && paths == nil {
+		delete(out, "paths") // paths is optional in 3.1
+	}
+	denormalizeOpenAPI31(out)
+	return out, nil
+}
+
+func denormalizeOpenAPI31(v any) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			denormalizeOpenAPI31(item)
+		}
+	case map[string]any:
+		denormalizeExclusiveBound(v, "exclusiveMinimum", "minimum")
+		denormalizeExclusiveBound(v, "exclusiveMaximum", "maximum")
+		for key, value := range v {
+			if _, ok := dataKeywords[key]; ok || isExtensionKey(key) {
+				continue
+			}
+			if key == "examples" || key == "links" {
+				continue
+			}
+			switch key {
+			case "items", "not":
+				v[key] = denormalizeBooleanSchema(value)
+			case "allOf", "anyOf", "oneOf":
+				if items, ok := value.([]any); ok {
+					for i, item := range items {
+						items[i] = denormalizeBooleanSchema(item)
+					}
+				}
+			}
+			if schemas, ok := value.(map[string]any); ok {
+				if _, ok := schemaMapKeywords[key]; ok {
+					// The keys are names, only the schemas are denormalized
+					for name, schema := range schemas {
+						denormalizeOpenAPI31(schema)
+						schemas[name] = denormalizeBooleanSchema(schema)
+					}
+					continue
+				}
+			}
+			denormalizeOpenAPI31(value)
+		}
+	}
+}
+
+// denormalizeBooleanSchema turns {not: {}} back into the boolean schema false.
+func denormalizeBooleanSchema(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) != 1 {
+		return v
+	}
+	if not, ok := m["not"].(map[string]any); ok && len(not) == 0 {
+		return false
+	}
+	return v
+}
+
+func denormalizeExclusiveBound(m map[string]any, exclusiveKey, inclusiveKey string) {
+	exclusive, ok := m[exclusiveKey].(bool)
+	if !ok {
+		return
+	}
+	if !exclusive {
+		delete(m, exclusiveKey)
+		return
+	}
+	if limit, ok := m[inclusiveKey].(float64); ok {
+		m[exclusiveKey] = limit
+		delete(m, inclusiveKey)
+	}
+}
@@ -0,0 +1,181 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+// marshalOpenAPI31 encodes the document in 3.1 form and decodes it back into a
+// generic map so tests can inspect the emitted keywords.
+func (suite *UtilsTestSuite) marshalOpenAPI31(doc *openapi3.T) map[string]any {
+	out, err := utils.MarshalOpenAPI31(doc)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	b, err := yaml.Marshal(out)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		suite.T().Fatal(err)
+	}
+	return m
+}
+
+// lookup walks a generic map along the given keys.
+func lookup(m map[string]any, keys ...string) any {
+	var v any = m
+	for _, key := range keys {
+		next, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = next[key]
+	}
+	return v
+}
+
+func TestIsOpenAPI31(t *testing.T) {
+	assert.True(t, utils.IsOpenAPI31([]byte("openapi: 3.1.0\n")))
+	assert.True(t, utils.IsOpenAPI31([]byte(`{"openapi": "3.1.1"}`)))
+	assert.False(t, utils.IsOpenAPI31([]byte("openapi: 3.0.3\n")))
+	assert.False(t, utils.IsOpenAPI31([]byte("swagger: '2.0'\n")))
+}
+
+func TestNormalizeOpenAPI31(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   string
+		expected map[string]any
+	}{
+		{
+			name:     "exclusive minimum only",
+			schema:   "exclusiveMinimum: 5",
+			expected: map[string]any{"minimum": 5, "exclusiveMinimum": true},
+		},
+		{
+			name:     "exclusive bound stricter than inclusive bound",
+			schema:   "minimum: 3\nexclusiveMinimum: 5",
+			expected: map[string]any{"minimum": 5, "exclusiveMinimum": true},
+		},
+		{
+			name:     "inclusive bound stricter than exclusive bound",
+			schema:   "maximum: 3\nexclusiveMaximum: 5",
+			expected: map[string]any{"maximum": 3, "exclusiveMaximum": false},
+		},
+		{
+			name:     "already 3.0 form",
+			schema:   "minimum: 3\nexclusiveMinimum: true",
+			expected: map[string]any{"minimum": 3, "exclusiveMinimum": true},
+		},
+		{
+			name:     "boolean schemas",
+			schema:   "items: false\nnot: true",
+			expected: map[string]any{"items": map[string]any{"not": map[string]any{}}, "not": map[string]any{}},
+		},
+		{
+			name:     "literal data is left alone",
+			schema:   "const:\n  exclusiveMinimum: 5\nexamples:\n  - items: false",
+			expected: map[string]any{"const": map[string]any{"exclusiveMinimum": 5}, "examples": []any{map[string]any{"items": false}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := utils.NormalizeOpenAPI31([]byte(tc.schema))
+			assert.NoError(t, err)
+			var actual map[string]any
+			assert.NoError(t, yaml.Unmarshal(out, &actual))
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestOpenAPI31RoundTrip() {
+	doc := suite.loadDoc(openAPI31File)
+	m := suite.marshalOpenAPI31(doc)
+
+	assert.Equal(suite.T(), "3.1.0", m["openapi"])
+	assert.Equal(suite.T(), "https://spec.openapis.org/oas/3.1/dialect/base", m["jsonSchemaDialect"])
+	assert.NotNil(suite.T(), lookup(m, "webhooks", "petAdopted", "post"))
+	assert.Equal(suite.T(), "MIT", lookup(m, "info", "license", "identifier"))
+
+	pet := []string{"components", "schemas", "Pet"}
+	assert.NotNil(suite.T(), lookup(m, append(pet, "$defs", "Tag")...))
+	assert.Equal(suite.T(), "dog", lookup(m, append(pet, "properties", "kind", "const")...))
+	assert.Equal(suite.T(), []any{"string", "null"}, lookup(m, append(pet, "properties", "name", "type")...))
+	assert.Equal(suite.T(), []any{"Rex", "x-not-an-extension"}, lookup(m, append(pet, "properties", "name", "examples")...))
+	assert.Equal(suite.T(), 30, lookup(m, append(pet, "properties", "age", "exclusiveMaximum")...))
+	assert.Equal(suite.T(), 0, lookup(m, append(pet, "properties", "age", "minimum")...))
+	assert.Equal(suite.T(), false, lookup(m, append(pet, "properties", "coordinates", "items")...))
+	assert.Len(suite.T(), lookup(m, append(pet, "properties", "coordinates", "prefixItems")...), 2)
+
+	limit := lookup(m, "paths", "/pets", "get", "parameters").([]any)[0].(map[string]any)
+	assert.Equal(suite.T(), 0, lookup(limit, "schema", "exclusiveMinimum"))
+	assert.Equal(suite.T(), 100, lookup(limit, "schema", "exclusiveMaximum"))
+	assert.Nil(suite.T(), lookup(limit, "schema", "minimum"))
+
+	// Example values and link parameters are literal data
+	ok := []string{"paths", "/pets", "get", "responses", "200"}
+	assert.Equal(suite.T(),
+		map[string]any{"items": map[string]any{"not": map[string]any{}}, "exclusiveMinimum": true, "minimum": 3},
+		lookup(m, append(ok, "content", "application/json", "examples", "literal", "value")...))
+	assert.Equal(suite.T(),
+		map[string]any{"items": false, "exclusiveMaximum": 3},
+		lookup(m, append(ok, "content", "application/json", "examples", "boolean", "value")...))
+	assert.Equal(suite.T(), map[string]any{"exclusiveMinimum": 2}, lookup(m, append(ok, "links", "owners", "parameters", "filter")...))
+	assert.Equal(suite.T(), map[string]any{"items": false}, lookup(m, append(ok, "links", "owners", "requestBody")...))
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_OpenAPI31() {
+	doc := suite.loadDoc(openAPI31File)
+	output, err := utils.SplitByPath(doc, map[string][]string{
+		"/pets":   {},
+		"/owners": {},
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.Contains(suite.T(), output.Components.Schemas, "Pet")
+	assert.Contains(suite.T(), output.Components.Schemas, "Owner", "Missing Owner (via Pet and the Owners path item)")
+	assert.Contains(suite.T(), output.Components.Schemas, "AdoptionEvent", "Missing AdoptionEvent (via webhooks)")
+	assert.NotContains(suite.T(), output.Components.Schemas, "UnusedSchema")
+
+	m := suite.marshalOpenAPI31(output)
+	assert.Equal(suite.T(), "https://spec.openapis.org/oas/3.1/dialect/base", m["jsonSchemaDialect"])
+	assert.NotNil(suite.T(), lookup(m, "webhooks", "petAdopted"))
+	assert.NotNil(suite.T(), lookup(m, "components", "pathItems", "Owners"))
+	assert.NotNil(suite.T(), lookup(m, "components", "schemas", "Pet", "$defs", "Tag"))
+	assert.Equal(suite.T(), "dog", lookup(m, "components", "schemas", "Pet", "properties", "kind", "const"))
+	assert.Nil(suite.T(), lookup(m, "paths", "/unused"))
+}
+
+func (suite *UtilsTestSuite) TestRemoveExtensions_OpenAPI31() {
+	doc := suite.loadDoc(openAPI31File)
+	utils.RemoveExtensions(doc, map[string]struct{}{})
+	m := suite.marshalOpenAPI31(doc)
+
+	assert.Nil(suite.T(), m["x-doc-extension"])
+	assert.Nil(suite.T(), lookup(m, "webhooks", "petAdopted", "post", "x-webhook-extension"))
+	assert.Nil(suite.T(), lookup(m, "components", "schemas", "Pet", "x-go-type"))
+	assert.Nil(suite.T(), lookup(m, "components", "schemas", "Pet", "$defs", "Tag", "x-internal"))
+
+	// 3.1 keywords are not extensions and must survive.
+	assert.NotNil(suite.T(), m["webhooks"])
+	assert.NotNil(suite.T(), m["jsonSchemaDialect"])
+	assert.Equal(suite.T(), "string", lookup(m, "components", "schemas", "Pet", "$defs", "Tag", "type"))
+	assert.Equal(suite.T(), "dog", lookup(m, "components", "schemas", "Pet", "properties", "kind", "const"))
+	assert.Equal(suite.T(), []any{"Rex", "x-not-an-extension"}, lookup(m, "components", "schemas", "Pet", "properties", "name", "examples"))
+
+	// Schema names are not extensions, whatever their prefix
+	label := lookup(m, "components", "schemas", "Pet", "$defs", "x-label").(map[string]any)
+	assert.Nil(suite.T(), label["x-internal"])
+	assert.Equal(suite.T(), []any{"x-name"}, label["required"])
+	assert.Equal(suite.T(), map[string]any{"type": "integer", "exclusiveMinimum": 0}, lookup(label, "properties", "x-name"))
+	assert.Equal(suite.T(), map[string]any{"type": "string"}, lookup(label, "patternProperties", "^x-"))
+	assert.Equal(suite.T(), 1, lookup(m, "components", "schemas", "Pet", "properties", "x-nickname", "exclusiveMinimum"))
+}
@@ -0,0 +1,153 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RegexPatternPrefix marks a path pattern as a regular expression.
+const RegexPatternPrefix = "re:"
+
+// PathPattern matches the paths of an OpenAPI document. A pattern is either:
+//   - a regular expression prefixed with "re:" (e.g. "re:^/admin/"), matched
+//     anywhere in the path unless anchored
+//   - a glob (e.g. "/users/*" or "/users/**") where "*" matches within a path
+//     segment, "**" matches across segments and "?" matches a single character;
+//     a trailing "/**" also matches the parent path itself
+//   - an exact path (e.g. "/users/{id}")
+type PathPattern struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// CompilePathPattern parses a path pattern.
+func CompilePathPattern(pattern string) (*PathPattern, error) {
+	if expr, ok := strings.CutPrefix(pattern, RegexPatternPrefix); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %v", ErrInvalidPathPattern, pattern, err)
+		}
+		return &PathPattern{pattern: pattern, re: re}, nil
+	}
+	if !isGlob(pattern) {
+		return &PathPattern{pattern: pattern}, nil
+	}
+	return &PathPattern{pattern: pattern, re: regexp.MustCompile(globToRegexp(pattern))}, nil
+}
+
+// String returns the pattern as it was given.
+func (p *PathPattern) String() string {
+	return p.pattern
+}
+
+// Match reports whether the path matches the pattern.
+func (p *PathPattern) Match(path string) bool {
+	if p.re == nil {
+		return p.pattern == path
+	}
+	return p.re.MatchString(path)
+}
+
+// ExactPathPattern returns a pattern matching only the given path, even when
+// the path contains glob characters.
+func ExactPathPattern(path string) string {
+	if !isGlob(path) && !strings.HasPrefix(path, RegexPatternPrefix) {
+		return path
+	}
+	return RegexPatternPrefix + "^" + regexp.QuoteMeta(path) + "$"
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// globToRegexp translates a glob into an anchored regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				b.WriteString(".*")
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '/':
+			if strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob) {
+				b.WriteString("(/.*)?")
+				i += 2
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// ExtensionPatterns matches extension names against a list of patterns, each
+// either:
+//   - a regular expression prefixed with "re:" (e.g. "re:^x-(amazon|aws)-"),
+//     matched anywhere in the name unless anchored
+//   - a glob (e.g. "x-amazon-apigateway-*") where "*" matches any characters
+//     and "?" a single character
+//   - an exact name (e.g. "x-go-type")
+//
+// A nil *ExtensionPatterns matches nothing.
+type ExtensionPatterns struct {
+	exact    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+// CompileExtensionPatterns parses extension name patterns. Empty patterns are
+// ignored.
+func CompileExtensionPatterns(patterns []string) (*ExtensionPatterns, error) {
+	p := &ExtensionPatterns{exact: make(map[string]struct{})}
+	for _, pattern := range patterns {
+		if err := p.add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *ExtensionPatterns) add(pattern string) error {
+	switch expr, isRegex := strings.CutPrefix(pattern, RegexPatternPrefix); {
+	case pattern == "":
+	case isRegex:
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("%w: '%s': %v", ErrInvalidExtensionPattern, pattern, err)
+		}
+		p.patterns = append(p.patterns, re)
+	case isGlob(pattern):
+		// Extension names hold no "/", so "*" and "**" both match any characters
+		p.patterns = append(p.patterns, regexp.MustCompile(globToRegexp(strings.ReplaceAll(pattern, "*", "**"))))
+	default:
+		p.exact[pattern] = struct{}{}
+	}
+	return nil
+}
+
+// Match reports whether the extension name matches one of the patterns.
+func (p *ExtensionPatterns) Match(name string) bool {
+	if p == nil {
+		return false
+	}
+	if _, ok := p.exact[name]; ok {
+		return true
+	}
+	for _, re := range p.patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathPatternMatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{name: "exact match", pattern: "/users/{id}", path: "/users/{id}", expected: true},
+		{name: "exact mismatch", pattern: "/users", path: "/users/{id}", expected: false},
+		{name: "star within segment", pattern: "/users/*", path: "/users/{id}", expected: true},
+		{name: "star does not cross segments", pattern: "/users/*", path: "/users/{id}/orders", expected: false},
+		{name: "double star crosses segments", pattern: "/users/**", path: "/users/{id}/orders", expected: true},
+		{name: "trailing double star matches parent", pattern: "/users/**", path: "/users", expected: true},
+		{name: "double star keeps prefix", pattern: "/users/**", path: "/usersettings", expected: false},
+		{name: "inner double star", pattern: "/**/orders", path: "/users/{id}/orders", expected: true},
+		{name: "question mark", pattern: "/v?/users", path: "/v2/users", expected: true},
+		{name: "braces are literal", pattern: "/users/{id}/*", path: "/users/42/orders", expected: false},
+		{name: "regex", pattern: "re:^/admin/", path: "/admin/users", expected: true},
+		{name: "regex is not anchored", pattern: "re:orders", path: "/users/{id}/orders", expected: true},
+		{name: "regex mismatch", pattern: "re:^/admin/", path: "/users/admin/", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pattern, err := utils.CompilePathPattern(tc.pattern)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.pattern, pattern.String())
+			assert.Equal(t, tc.expected, pattern.Match(tc.path))
+		})
+	}
+}
+
+func TestCompilePathPatternInvalidRegex(t *testing.T) {
+	_, err := utils.CompilePathPattern("re:^/admin/(")
+	assert.ErrorIs(t, err, utils.ErrInvalidPathPattern)
+}
+
+func TestExactPathPattern(t *testing.T) {
+	for _, path := range []string{"/users/{id}", "/files/*", "/search?"} {
+		pattern, err := utils.CompilePathPattern(utils.ExactPathPattern(path))
+		assert.NoError(t, err)
+		assert.True(t, pattern.Match(path), path)
+		assert.False(t, pattern.Match(path+"/x"), path)
+	}
+	assert.Equal(t, "/users/{id}", utils.ExactPathPattern("/users/{id}"))
+}
+
+func TestExtensionPatternsMatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		patterns []string
+		ext      string
+		expected bool
+	}{
+		{name: "exact match", patterns: []string{"x-go-type"}, ext: "x-go-type", expected: true},
+		{name: "exact mismatch", patterns: []string{"x-go"}, ext: "x-go-type", expected: false},
+		{name: "prefix glob", patterns: []string{"x-amazon-apigateway-*"}, ext: "x-amazon-apigateway-integration", expected: true},
+		{name: "prefix glob mismatch", patterns: []string{"x-amazon-apigateway-*"}, ext: "x-amazon-other", expected: false},
+		{name: "inner glob", patterns: []string{"x-*-internal"}, ext: "x-speakeasy-internal", expected: true},
+		{name: "glob is anchored", patterns: []string{"x-speakeasy-*"}, ext: "x-not-speakeasy-name", expected: false},
+		{name: "question mark", patterns: []string{"x-v?"}, ext: "x-v2", expected: true},
+		{name: "regex", patterns: []string{"re:^x-(amazon|aws)-"}, ext: "x-aws-region", expected: true},
+		{name: "regex is not anchored", patterns: []string{"re:internal"}, ext: "x-speakeasy-internal", expected: true},
+		{name: "any pattern", patterns: []string{"x-go-type", "x-speakeasy-*"}, ext: "x-speakeasy-name", expected: true},
+		{name: "empty pattern", patterns: []string{""}, ext: "x-go-type", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			patterns, err := utils.CompileExtensionPatterns(tc.patterns)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, patterns.Match(tc.ext))
+		})
+	}
+
+	var patterns *utils.ExtensionPatterns
+	assert.False(t, patterns.Match("x-go-type"), "A nil pattern list matches nothing")
+}
+
+func TestCompileExtensionPatternsInvalidRegex(t *testing.T) {
+	_, err := utils.CompileExtensionPatterns([]string{"x-go-type", "re:^x-(aws"})
+	assert.ErrorIs(t, err, utils.ErrInvalidExtensionPattern)
+}
@@ -1,204 +1,124 @@
 package utils
 
-import "github.com/getkin/kin-openapi/openapi3"
+import (
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
 
 // RemoveExtensions removes all extensions from the OpenAPI document,
 // except those specified in the exclude map.
-// The exclude map contains keys of extensions that should not be removed.
+// The exclude map contains keys of extensions that should not be removed,
+// either exact names or patterns (see ExtensionPatterns). A key that is not
+// a valid pattern is kept as an exact name.
 func RemoveExtensions(doc *openapi3.T, exclude map[string]struct{}) {
-	if doc == nil {
-		return
-	}
-
-	// Remove extensions from the OpenAPI document itself
-	removeExt(doc.Extensions, exclude)
-
-	// Remove components extensions
-	removeComponentsExtensions(doc.Components, exclude)
-
-	// Remove paths extensions
-	doc.Paths.Extensions = nil
-	for _, pathItem := range doc.Paths.Map() {
-		pathItem.Extensions = nil
-		for _, operation := range pathItem.Operations() {
-			// Remove extensions from each operation
-			removeExt(operation.Extensions, exclude) // Corrected: use removeExt helper
-
-			// Remove parameters from the operation
-			for _, param := range operation.Parameters {
-				removeParameterExtensions(param, exclude)
-			}
-
-			// Remove request body extensions
-			if operation.RequestBody != nil {
-				removeRequestBodyExtensions(operation.RequestBody, exclude)
-			}
-
-			// Remove responses extensions
-			for _, response := range operation.Responses.Map() {
-				removeResponseExtensions(response, exclude)
-			}
+	keep := &ExtensionPatterns{exact: make(map[string]struct{})}
+	for key := range exclude {
+		if err := keep.add(key); err != nil {
+			keep.exact[key] = struct{}{}
 		}
 	}
+	RemoveExtensionsMatching(doc, keep)
 }
 
-func removeExt(ext map[string]any, exclude map[string]struct{}) {
-	if ext == nil {
-		return
-	}
-	for key := range ext {
-		if _, ok := exclude[key]; !ok {
-			delete(ext, key)
-		}
-	}
+// RemoveExtensionsMatching removes all extensions from the OpenAPI document,
+// except those matching the keep patterns. Every object of the document is
+// visited, including the components, callbacks and OpenAPI 3.1 keywords.
+func RemoveExtensionsMatching(doc *openapi3.T, keep *ExtensionPatterns) {
+	RemoveExtensionsWithRules(doc, nil, keep)
 }
 
-func removeComponentsExtensions(components *openapi3.Components, exclude map[string]struct{}) {
-	if components == nil {
-		return
-	}
-
-	for _, schema := range components.Schemas {
-		removeSchemaExtensions(schema, exclude)
-	}
-
-	for _, response := range components.Responses {
-		removeResponseExtensions(response, exclude)
-	}
-
-	for _, parameter := range components.Parameters {
-		removeParameterExtensions(parameter, exclude)
-	}
-
-	for _, requestBody := range components.RequestBodies {
-		removeRequestBodyExtensions(requestBody, exclude)
-	}
-
-	for _, header := range components.Headers {
-		removeHeaderExtensions(header, exclude)
-	}
-
-	for _, securityScheme := range components.SecuritySchemes {
-		removeSecuritySchemeExtensions(securityScheme, exclude)
-	}
+// DropExtensionsMatching removes the extensions matching the drop patterns
+// from the OpenAPI document and keeps all the others. It is the opposite of
+// RemoveExtensionsMatching and visits the same objects.
+func DropExtensionsMatching(doc *openapi3.T, drop *ExtensionPatterns) {
+	DropExtensionsWithRules(doc, nil, drop)
 }
-func removeSchemaExtensions(schema *openapi3.SchemaRef, exclude map[string]struct{}) {
-	if schema == nil {
-		return
-	}
-	// Remove extensions from the schema reference itself
-	removeExt(schema.Extensions, exclude)
-
-	if schema.Value == nil {
-		return
-	}
-	// Remove extensions from the schema itself
-	removeExt(schema.Value.Extensions, exclude)
-
-	// Recursively remove extensions from properties
-	for _, prop := range schema.Value.Properties {
-		removeSchemaExtensions(prop, exclude)
-	}
 
-	// Remove extensions from items if it's an array
-	if schema.Value.Items != nil {
-		removeSchemaExtensions(schema.Value.Items, exclude)
-	}
-
-	// Remove extensions from allOf, oneOf, anyOf
-	for _, ref := range schema.Value.AllOf {
-		removeSchemaExtensions(ref, exclude)
-	}
-	for _, ref := range schema.Value.OneOf {
-		removeSchemaExtensions(ref, exclude)
-	}
-	for _, ref := range schema.Value.AnyOf {
-		removeSchemaExtensions(ref, exclude)
-	}
+// RemoveExtensionsWithRules removes the extensions of every object of the
+// document as decided by the first rule applying to the object and naming the
+// extension. The extensions no rule decides for are removed unless they match
+// the keep patterns, as RemoveExtensionsMatching does.
+func RemoveExtensionsWithRules(doc *openapi3.T, rules ExtensionRules, keep *ExtensionPatterns) {
+	removeExtensionsWhere(doc, func(node *Node, key string) bool {
+		if remove, decided := rules.decide(node, key); decided {
+			return remove
+		}
+		return !keep.Match(key)
+	})
 }
-func removeResponseExtensions(response *openapi3.ResponseRef, exclude map[string]struct{}) {
-	if response == nil {
-		return
-	}
-	// Remove extensions from the response reference itself
-	removeExt(response.Extensions, exclude)
 
-	if response.Value == nil {
-		return
-	}
-	// Remove extensions from the response itself
-	removeExt(response.Value.Extensions, exclude)
-
-	// Remove extensions from headers
-	for _, header := range response.Value.Headers {
-		removeHeaderExtensions(header, exclude)
-	}
-
-	// Remove extensions from content
-	for _, mediaType := range response.Value.Content {
-		removeSchemaExtensions(mediaType.Schema, exclude)
-	}
+// DropExtensionsWithRules is RemoveExtensionsWithRules in the deny-list mode:
+// the extensions no rule decides for are kept unless they match the drop
+// patterns, as DropExtensionsMatching does.
+func DropExtensionsWithRules(doc *openapi3.T, rules ExtensionRules, drop *ExtensionPatterns) {
+	removeExtensionsWhere(doc, func(node *Node, key string) bool {
+		if remove, decided := rules.decide(node, key); decided {
+			return remove
+		}
+		return drop.Match(key)
+	})
 }
-func removeHeaderExtensions(header *openapi3.HeaderRef, exclude map[string]struct{}) {
-	if header == nil {
-		return
-	}
-	// Remove extensions from the header reference itself
-	removeExt(header.Extensions, exclude)
 
-	if header.Value == nil {
+// removeExtensionsWhere removes the extensions for which remove returns true
+// from every object of the document.
+func removeExtensionsWhere(doc *openapi3.T, remove func(node *Node, key string) bool) {
+	if doc == nil {
 		return
 	}
-	// Remove extensions from the header itself
-	header.Value.Extensions = nil
-
-	// Remove extensions from schema
-	removeSchemaExtensions(header.Value.Schema, exclude)
+	_ = walkDefinitions(doc, func(node *Node) error {
+		removeNode := func(key string) bool { return remove(node, key) }
+		removeExt(node.Extensions, removeNode, rawPathItemsKey(node.Kind))
+		removeExt(node.RefExtensions, removeNode, "")
+		return nil
+	}) // enter never fails
 }
-func removeParameterExtensions(parameter *openapi3.ParameterRef, exclude map[string]struct{}) {
-	if parameter == nil {
-		return
-	}
-	// Remove extensions from the parameter reference itself
-	removeExt(parameter.Extensions, exclude)
 
-	if parameter.Value == nil {
-		return
+// walkDefinitions calls enter for every object of the document. The
+// referenced objects are walked where they are defined, so each object is seen
+// with a single location. The targets of external references are not written
+// out, only the references are.
+func walkDefinitions(doc *openapi3.T, enter func(node *Node) error) error {
+	walker := &Walker{
+		Refs: SkipRefs,
+		Enter: func(node *Node) error {
+			if node.Ref != "" {
+				// The extensions of the target are handled where it is defined
+				node.Extensions = nil
+			}
+			return enter(node)
+		},
 	}
-	// Remove extensions from the parameter itself
-	removeExt(parameter.Value.Extensions, exclude)
-
-	// Remove extensions from schema
-	// removeSchemaExtensions(parameter.Value.Schema, exclude)
+	return walker.Walk(doc)
 }
-func removeRequestBodyExtensions(requestBody *openapi3.RequestBodyRef, exclude map[string]struct{}) {
-	if requestBody == nil {
-		return
-	}
-	// Remove extensions from the request body reference itself
-	removeExt(requestBody.Extensions, exclude)
-
-	if requestBody.Value == nil {
-		return
-	}
-	// Remove extensions from the request body itself
-	removeExt(requestBody.Value.Extensions, exclude)
 
-	// Remove extensions from content
-	for _, mediaType := range requestBody.Value.Content {
-		removeSchemaExtensions(mediaType.Schema, exclude)
+// removeExt removes the extensions of ext for which remove returns true. The
+// raw path items under walked (see rawPathItemsKey) are left to their own
+// nodes.
+func removeExt(ext map[string]any, remove func(key string) bool, walked string) {
+	for key, value := range ext {
+		if key == walked {
+			continue
+		}
+		if isExtensionKey(key) {
+			if remove(key) {
+				delete(ext, key)
+			}
+			continue
+		}
+		// Not an extension but an OpenAPI 3.1 keyword kept by the openapi3 model
+		// (e.g. $defs), remove the extensions of the objects nested in it instead
+		walkRawObjects(key, value, func(object map[string]any) {
+			for key := range object {
+				if isExtensionKey(key) && remove(key) {
+					delete(object, key)
+				}
+			}
+		})
 	}
 }
-func removeSecuritySchemeExtensions(securityScheme *openapi3.SecuritySchemeRef, exclude map[string]struct{}) {
-	if securityScheme == nil {
-		return
-	}
-	// Remove extensions from the security scheme reference itself
-	removeExt(securityScheme.Extensions, exclude)
 
-	if securityScheme.Value == nil {
-		return
-	}
-	// Remove extensions from the security scheme itself
-	removeExt(securityScheme.Value.Extensions, exclude)
+// isExtensionKey reports whether key is a specification extension ("x-" prefix).
+func isExtensionKey(key string) bool {
+	return strings.HasPrefix(key, "x-")
 }
@@ -1,6 +1,8 @@
 package utils_test
 
 import (
+	"strings"
+
 	"github.com/0x726f6f6b6965/openapi-fmt/utils"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
@@ -73,6 +75,117 @@ func (suite *UtilsTestSuite) TestRemoveExtensionsWithExcludes() {
 	assert.NotContains(suite.T(), str, "x-42c-sample", "Non-excluded extension 'x-42c-sample' should not be present")
 }
 
+func (suite *UtilsTestSuite) TestRemoveExtensionsWithPatterns() {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(removeExtensionsFile)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	keep, err := utils.CompileExtensionPatterns([]string{"x-go-*", "re:^x-42c-"})
+	assert.NoError(suite.T(), err)
+	utils.RemoveExtensionsMatching(doc, keep)
+	f, err := doc.MarshalYAML()
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	str := string(b)
+	assert.Contains(suite.T(), str, "x-go-type", "Extension matching 'x-go-*' should be present")
+	assert.Contains(suite.T(), str, "x-42c-sample", "Extension matching 're:^x-42c-' should be present")
+	assert.NotContains(suite.T(), str, "x-another-to-remove")
+	assert.NotContains(suite.T(), str, "x-comp-schema-ext")
+}
+
+func (suite *UtilsTestSuite) TestRemoveExtensionsWithGlobExcludes() {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(removeExtensionsFile)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	// The exclude map accepts patterns as well, an invalid one is an exact name
+	utils.RemoveExtensions(doc, map[string]struct{}{"x-*-schema-ext": {}, "re:(": {}})
+	f, err := doc.MarshalYAML()
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+
+	str := string(b)
+	assert.Contains(suite.T(), str, "x-comp-schema-ext")
+	assert.Contains(suite.T(), str, "x-other-schema-ext")
+	assert.NotContains(suite.T(), str, "x-go-type")
+}
+
+func (suite *UtilsTestSuite) TestRemoveExtensionsEveryObject() {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(removeAllExtensionsFile)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	marshal := func() string {
+		f, err := doc.MarshalYAML()
+		if err != nil {
+			suite.T().Fatal(err)
+		}
+		b, err := yaml.Marshal(f)
+		if err != nil {
+			suite.T().Fatal(err)
+		}
+		return string(b)
+	}
+	before := marshal()
+	kept := strings.Count(before, "x-keep:")
+	assert.Equal(suite.T(), strings.Count(before, "x-drop:"), kept, "Every extension of the fixture should be loaded")
+
+	utils.RemoveExtensions(doc, map[string]struct{}{"x-keep": {}})
+	after := marshal()
+	assert.NotContains(suite.T(), after, "x-drop", "Extensions were not removed from every object")
+	assert.Equal(suite.T(), kept, strings.Count(after, "x-keep:"), "The kept extension should be kept on every object")
+
+	utils.RemoveExtensions(doc, map[string]struct{}{})
+	assert.NotContains(suite.T(), marshal(), "x-", "Extensions were not removed from every object")
+}
+
+func (suite *UtilsTestSuite) TestDropExtensionsMatching() {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(removeAllExtensionsFile)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	marshal := func() string {
+		f, err := doc.MarshalYAML()
+		if err != nil {
+			suite.T().Fatal(err)
+		}
+		b, err := yaml.Marshal(f)
+		if err != nil {
+			suite.T().Fatal(err)
+		}
+		return string(b)
+	}
+	kept := strings.Count(marshal(), "x-keep:")
+
+	drop, err := utils.CompileExtensionPatterns([]string{"x-dr*"})
+	assert.NoError(suite.T(), err)
+	utils.DropExtensionsMatching(doc, drop)
+	after := marshal()
+	assert.NotContains(suite.T(), after, "x-drop", "Dropped extensions were not removed from every object")
+	assert.Equal(suite.T(), kept, strings.Count(after, "x-keep:"), "The other extensions should be kept on every object")
+
+	// Nothing is removed without patterns
+	utils.DropExtensionsMatching(doc, nil)
+	assert.Equal(suite.T(), after, marshal())
+}
+
 func (suite *UtilsTestSuite) TestRemoveExtensionsNoExtensionsPresent() {
 	loader := openapi3.NewLoader()
 	minimalContent := `
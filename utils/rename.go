@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ExtensionMapping renames an extension, or promotes it into a standard field
+// of the objects carrying it when To is not an extension name (e.g. x-nullable
+// to nullable, see PromotedFields).
+type ExtensionMapping struct {
+	From string
+	To   string
+}
+
+// PromotedFields are the standard fields an extension can be promoted into.
+var PromotedFields = []string{"deprecated", "example", "nullable", "readOnly", "writeOnly"}
+
+// NewExtensionMapping checks a mapping from an extension to another extension
+// or to one of the PromotedFields.
+func NewExtensionMapping(from string, to string) (ExtensionMapping, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	switch {
+	case !isExtensionKey(from):
+		return ExtensionMapping{}, fmt.Errorf("%w: '%s' is not an extension name", ErrInvalidExtensionMapping, from)
+	case from == to:
+		return ExtensionMapping{}, fmt.Errorf("%w: '%s' is mapped to itself", ErrInvalidExtensionMapping, from)
+	case !isExtensionKey(to) && !slices.Contains(PromotedFields, to):
+		return ExtensionMapping{}, fmt.Errorf("%w: '%s' is neither an extension name nor one of %s", ErrInvalidExtensionMapping, to, strings.Join(PromotedFields, ", "))
+	}
+	return ExtensionMapping{From: from, To: to}, nil
+}
+
+// ParseExtensionMapping parses a "from=to" mapping, e.g.
+// "x-go-name=x-oapi-codegen-extra-tags" or "x-nullable=nullable".
+func ParseExtensionMapping(expr string) (ExtensionMapping, error) {
+	from, to, ok := strings.Cut(expr, "=")
+	if !ok {
+		return ExtensionMapping{}, fmt.Errorf("%w: '%s' (expected x-from=x-to or x-from=field)", ErrInvalidExtensionMapping, expr)
+	}
+	return NewExtensionMapping(from, to)
+}
+
+// String returns the mapping as an expression accepted by ParseExtensionMapping.
+func (m ExtensionMapping) String() string {
+	return m.From + "=" + m.To
+}
+
+// RenameExtensions applies the mappings to the extensions of every object of
+// the document, visiting the same objects as RemoveExtensions. The mappings
+// apply to the original names (with x-a=x-b and x-b=x-c, x-a becomes x-b and
+// x-b becomes x-c), and a renamed value replaces an existing one.
+//
+// A promoted extension is moved into the field of the objects having it (e.g.
+// nullable on schemas) and left as it is on the others. Boolean fields accept
+// booleans and their string form ("true"), other values are reported with
+// ErrInvalidExtensionValue and stop the renaming.
+func RenameExtensions(doc *openapi3.T, mappings []ExtensionMapping) error {
+	if doc == nil {
+		return ErrOpenAPINotFound
+	}
+	renames := make(map[string]string, len(mappings))
+	for _, mapping := range mappings {
+		mapping, err := NewExtensionMapping(mapping.From, mapping.To)
+		if err != nil {
+			return err
+		}
+		if to, ok := renames[mapping.From]; ok && to != mapping.To {
+			return fmt.Errorf("%w: '%s' is mapped to both '%s' and '%s'", ErrInvalidExtensionMapping, mapping.From, to, mapping.To)
+		}
+		renames[mapping.From] = mapping.To
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+	return walkDefinitions(doc, func(node *Node) error {
+		renameRefExt(node.RefExtensions, renames)
+		return renameExt(node, renames)
+	})
+}
+
+// renameExt renames the extensions of the node and promotes those it has a
+// field for.
+func renameExt(node *Node, renames map[string]string) error {
+	ext := node.Extensions
+	if ext == nil {
+		return nil
+	}
+	moved := make(map[string]any)
+	for _, key := range sortedKeys(ext) {
+		value := ext[key]
+		if key == rawPathItemsKey(node.Kind) {
+			continue // Walked as path items
+		}
+		if !isExtensionKey(key) {
+			// An OpenAPI 3.1 keyword kept by the openapi3 model (e.g. $defs), rename the extensions of the objects nested in it.
+			// Raw values are only renamed, never promoted
+			walkRawObjects(key, value, func(object map[string]any) {
+				renameRefExt(object, renames)
+			})
+			continue
+		}
+		to, ok := renames[key]
+		if !ok {
+			continue
+		}
+		if !isExtensionKey(to) {
+			promoted, err := promoteExtension(node, to, value)
+			if err != nil {
+				return fmt.Errorf("%w: %s at %s: %w", ErrInvalidExtensionValue, key, node.Pointer, err)
+			}
+			if promoted {
+				delete(ext, key)
+			}
+			continue
+		}
+		delete(ext, key)
+		moved[to] = value
+	}
+	for key, value := range moved {
+		ext[key] = value
+	}
+	return nil
+}
+
+// renameRefExt renames the extensions of a reference object or of a raw
+// OpenAPI 3.1 object, which have no standard field to be promoted into.
+func renameRefExt(ext map[string]any, renames map[string]string) {
+	moved := make(map[string]any)
+	for key, value := range ext {
+		if to, ok := renames[key]; ok && isExtensionKey(to) {
+			delete(ext, key)
+			moved[to] = value
+		}
+	}
+	for key, value := range moved {
+		ext[key] = value
+	}
+}
+
+// promoteExtension sets the field of the node to the extension value, and
+// reports whether the node has such a field.
+func promoteExtension(node *Node, field string, value any) (bool, error) {
+	switch field {
+	case "example":
+		switch object := node.Value.(type) {
+		case *openapi3.Schema:
+			object.Example = value
+		case *openapi3.Parameter:
+			object.Example = value
+		case *openapi3.Header:
+			object.Example = value
+		case *openapi3.MediaType:
+			object.Example = value
+		default:
+			return false, nil
+		}
+		return true, nil
+	}
+
+	var target *bool
+	switch object := node.Value.(type) {
+	case *openapi3.Schema:
+		switch field {
+		case "deprecated":
+			target = &object.Deprecated
+		case "nullable":
+			target = &object.Nullable
+		case "readOnly":
+			target = &object.ReadOnly
+		case "writeOnly":
+			target = &object.WriteOnly
+		}
+	case *openapi3.Operation:
+		if field == "deprecated" {
+			target = &object.Deprecated
+		}
+	case *openapi3.Parameter:
+		if field == "deprecated" {
+			target = &object.Deprecated
+		}
+	case *openapi3.Header:
+		if field == "deprecated" {
+			target = &object.Deprecated
+		}
+	}
+	if target == nil {
+		return false, nil
+	}
+	b, err := boolValue(value)
+	if err != nil {
+		return false, err
+	}
+	*target = b
+	return true, nil
+}
+
+// boolValue converts a boolean or its string form.
+func boolValue(value any) (bool, error) {
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	case string:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%v is not a boolean", value)
+}
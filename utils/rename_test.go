@@ -0,0 +1,130 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExtensionMapping(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expr     string
+		expected utils.ExtensionMapping
+		err      string
+	}{
+		{name: "rename", expr: "x-go-name=x-oapi-codegen-extra-tags", expected: utils.ExtensionMapping{From: "x-go-name", To: "x-oapi-codegen-extra-tags"}},
+		{name: "promote", expr: " x-nullable = nullable ", expected: utils.ExtensionMapping{From: "x-nullable", To: "nullable"}},
+		{name: "missing target", expr: "x-go-name", err: "expected x-from=x-to"},
+		{name: "not an extension", expr: "nullable=x-nullable", err: "'nullable' is not an extension name"},
+		{name: "unknown field", expr: "x-title=title", err: "'title' is neither an extension name nor one of"},
+		{name: "itself", expr: "x-go-name=x-go-name", err: "mapped to itself"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mapping, err := utils.ParseExtensionMapping(tc.expr)
+			if tc.err != "" {
+				assert.ErrorIs(t, err, utils.ErrInvalidExtensionMapping)
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, mapping)
+			assert.Equal(t, tc.expected.From+"="+tc.expected.To, mapping.String())
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestRenameExtensions() {
+	doc := suite.loadDoc(renameExtensionsFile)
+	delete(doc.Components.Schemas["User"].Value.Properties, "nickname") // see TestRenameExtensionsErrors
+
+	err := utils.RenameExtensions(doc, []utils.ExtensionMapping{
+		{From: "x-go-name", To: "x-oapi-codegen-extra-tags"},
+		{From: "x-nullable", To: "nullable"},
+		{From: "x-deprecated", To: "deprecated"},
+		{From: "x-example", To: "example"},
+	})
+	assert.NoError(suite.T(), err)
+
+	operation := doc.Paths.Find("/users").Get
+	assert.Equal(suite.T(), "ListUsers", operation.Extensions["x-oapi-codegen-extra-tags"])
+	assert.True(suite.T(), operation.Deprecated)
+	// Operations have no nullable field, the extension is left as it is
+	assert.Equal(suite.T(), []string{"x-nullable", "x-oapi-codegen-extra-tags"}, extensionNames(operation.Extensions))
+
+	param := operation.Parameters[0].Value
+	assert.True(suite.T(), param.Deprecated, "The string form of a boolean should be promoted")
+	assert.Equal(suite.T(), float64(10), param.Example)
+	assert.Empty(suite.T(), param.Extensions)
+
+	user := doc.Components.Schemas["User"].Value
+	assert.Equal(suite.T(), map[string]any{"x-oapi-codegen-extra-tags": "User"}, user.Extensions, "The renamed value should replace the existing one")
+	name := user.Properties["name"].Value
+	assert.True(suite.T(), name.Nullable)
+	assert.Equal(suite.T(), map[string]any{"x-oapi-codegen-extra-tags": "Name"}, name.Extensions)
+	assert.Equal(suite.T(), map[string]any{"x-oapi-codegen-extra-tags": "Info"}, doc.Info.Extensions)
+}
+
+func (suite *UtilsTestSuite) TestRenameExtensionsSwap() {
+	doc := suite.loadDoc(renameExtensionsFile)
+	delete(doc.Components.Schemas["User"].Value.Properties, "nickname") // see TestRenameExtensionsErrors
+
+	err := utils.RenameExtensions(doc, []utils.ExtensionMapping{
+		{From: "x-go-name", To: "x-oapi-codegen-extra-tags"},
+		{From: "x-oapi-codegen-extra-tags", To: "x-go-name"},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]any{"x-go-name": "replaced", "x-oapi-codegen-extra-tags": "User"}, doc.Components.Schemas["User"].Value.Extensions)
+}
+
+func (suite *UtilsTestSuite) TestRenameExtensionsWebhooks() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	paid := webhookItems(doc)["invoice.paid"].(map[string]any)
+	paid["post"].(map[string]any)["x-go-name"] = "InvoicePaid"
+
+	err := utils.RenameExtensions(doc, []utils.ExtensionMapping{
+		{From: "x-go-name", To: "x-oapi-codegen-extra-tags"},
+		{From: "x-oapi-codegen-extra-tags", To: "x-go-name"},
+	})
+	assert.NoError(suite.T(), err)
+
+	// Renamed once, as a webhook operation
+	post := webhookItems(doc)["invoice.paid"].(map[string]any)["post"].(map[string]any)
+	assert.Equal(suite.T(), "InvoicePaid", post["x-oapi-codegen-extra-tags"])
+	assert.NotContains(suite.T(), post, "x-go-name")
+}
+
+func (suite *UtilsTestSuite) TestRenameExtensionsOpenAPI31() {
+	doc := suite.loadDoc(openAPI31File)
+	err := utils.RenameExtensions(doc, []utils.ExtensionMapping{
+		{From: "x-name", To: "x-other"},
+		{From: "x-internal", To: "x-private"},
+	})
+	assert.NoError(suite.T(), err)
+
+	// Schema names are not extensions, whatever their prefix
+	label := lookup(suite.marshalOpenAPI31(doc), "components", "schemas", "Pet", "$defs", "x-label").(map[string]any)
+	assert.Equal(suite.T(), true, label["x-private"])
+	assert.NotContains(suite.T(), label, "x-internal")
+	assert.Equal(suite.T(), []any{"x-name"}, label["required"])
+	assert.Equal(suite.T(), map[string]any{"type": "integer", "exclusiveMinimum": 0, "x-private": true}, lookup(label, "properties", "x-name"))
+}
+
+func (suite *UtilsTestSuite) TestRenameExtensionsErrors() {
+	doc := suite.loadDoc(renameExtensionsFile)
+
+	err := utils.RenameExtensions(doc, []utils.ExtensionMapping{{From: "x-nullable", To: "nullable"}})
+	assert.ErrorIs(suite.T(), err, utils.ErrInvalidExtensionValue)
+	assert.ErrorContains(suite.T(), err, "x-nullable at /components/schemas/User/properties/nickname: maybe is not a boolean")
+
+	err = utils.RenameExtensions(doc, []utils.ExtensionMapping{{From: "x-go-name", To: "x-a"}, {From: "x-go-name", To: "x-b"}})
+	assert.ErrorIs(suite.T(), err, utils.ErrInvalidExtensionMapping)
+
+	err = utils.RenameExtensions(doc, []utils.ExtensionMapping{{From: "x-go-name", To: "title"}})
+	assert.ErrorIs(suite.T(), err, utils.ErrInvalidExtensionMapping)
+
+	assert.ErrorIs(suite.T(), utils.RenameExtensions(nil, nil), utils.ErrOpenAPINotFound)
+}
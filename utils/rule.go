@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ExtensionRule keeps or removes extensions on some objects of a document only:
+// those of the given kinds (e.g. KindSchema) and at locations matching the
+// JSON pointer patterns (e.g. "/paths/**/get" or "/webhooks/*/post", see
+// PathPattern). A rule without kinds or without pointers is not restricted by
+// them.
+type ExtensionRule struct {
+	kinds    []NodeKind
+	pointers []*PathPattern
+	keep     *ExtensionPatterns
+	drop     *ExtensionPatterns
+}
+
+// NewExtensionRule parses a rule keeping or dropping (exactly one of the two)
+// the extensions matching the patterns (see ExtensionPatterns) on the objects
+// of the kinds and at the pointers.
+func NewExtensionRule(kinds []string, pointers []string, keep []string, drop []string) (*ExtensionRule, error) {
+	if len(keep) > 0 && len(drop) > 0 {
+		return nil, fmt.Errorf("%w: a rule either keeps or drops extensions, not both", ErrInvalidExtensionRule)
+	}
+	if len(keep) == 0 && len(drop) == 0 {
+		return nil, fmt.Errorf("%w: a rule must keep or drop extensions", ErrInvalidExtensionRule)
+	}
+	r := &ExtensionRule{}
+	for _, kind := range kinds {
+		if _, ok := nodeKinds[NodeKind(kind)]; !ok {
+			return nil, fmt.Errorf("%w: unknown object kind '%s'", ErrInvalidExtensionRule, kind)
+		}
+		r.kinds = append(r.kinds, NodeKind(kind))
+	}
+	for _, pointer := range pointers {
+		pattern, err := CompilePathPattern(pointer)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidExtensionRule, err)
+		}
+		r.pointers = append(r.pointers, pattern)
+	}
+	var err error
+	if r.keep, err = compileRulePatterns(keep); err != nil {
+		return nil, err
+	}
+	if r.drop, err = compileRulePatterns(drop); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func compileRulePatterns(patterns []string) (*ExtensionPatterns, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	compiled, err := CompileExtensionPatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidExtensionRule, err)
+	}
+	return compiled, nil
+}
+
+// Applies reports whether the rule applies to the node.
+func (r *ExtensionRule) Applies(node *Node) bool {
+	if len(r.kinds) > 0 && !slices.Contains(r.kinds, node.Kind) {
+		return false
+	}
+	if len(r.pointers) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.pointers, func(pattern *PathPattern) bool {
+		return pattern.Match(node.Pointer)
+	})
+}
+
+// decide reports whether the rule removes the extension, and whether it
+// decides for the extension at all.
+func (r *ExtensionRule) decide(name string) (remove bool, decided bool) {
+	if r.keep.Match(name) {
+		return false, true
+	}
+	if r.drop.Match(name) {
+		return true, true
+	}
+	return false, false
+}
+
+// ExtensionRules are evaluated in order at each object: the first rule that
+// applies to the object and names an extension decides whether it is kept.
+type ExtensionRules []*ExtensionRule
+
+// decide reports whether the rules remove the extension of the node, and
+// whether one of them decides for it.
+func (rules ExtensionRules) decide(node *Node, name string) (remove bool, decided bool) {
+	for _, rule := range rules {
+		if !rule.Applies(node) {
+			continue
+		}
+		if remove, decided := rule.decide(name); decided {
+			return remove, true
+		}
+	}
+	return false, false
+}
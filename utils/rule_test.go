@@ -0,0 +1,148 @@
+package utils_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExtensionRule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		kinds    []string
+		pointers []string
+		keep     []string
+		drop     []string
+		err      string
+	}{
+		{name: "keep by kind", kinds: []string{"schema", "operation"}, keep: []string{"x-go-*"}},
+		{name: "drop by pointer", pointers: []string{"/paths/**/get", "re:^/components/"}, drop: []string{"x-go-type"}},
+		{name: "keep and drop", keep: []string{"x-go-type"}, drop: []string{"x-internal"}, err: "not both"},
+		{name: "neither keep nor drop", kinds: []string{"schema"}, err: "must keep or drop"},
+		{name: "unknown kind", kinds: []string{"schemas"}, keep: []string{"x-go-type"}, err: "unknown object kind 'schemas'"},
+		{name: "invalid pointer", pointers: []string{"re:^/paths/("}, keep: []string{"x-go-type"}, err: "invalid path pattern"},
+		{name: "invalid extension", keep: []string{"re:^x-(go"}, err: "invalid extension pattern"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := utils.NewExtensionRule(tc.kinds, tc.pointers, tc.keep, tc.drop)
+			if tc.err != "" {
+				assert.ErrorIs(t, err, utils.ErrInvalidExtensionRule)
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, rule)
+		})
+	}
+}
+
+func TestExtensionRuleApplies(t *testing.T) {
+	rule, err := utils.NewExtensionRule([]string{"operation"}, []string{"/paths/**/get"}, []string{"x-codeSamples"}, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, rule.Applies(&utils.Node{Kind: utils.KindOperation, Pointer: "/paths/~1users~1{id}/get"}))
+	assert.False(t, rule.Applies(&utils.Node{Kind: utils.KindOperation, Pointer: "/paths/~1users/post"}))
+	assert.False(t, rule.Applies(&utils.Node{Kind: utils.KindPathItem, Pointer: "/paths/get"}))
+}
+
+func (suite *UtilsTestSuite) newExtensionRule(kinds, pointers, keep, drop []string) *utils.ExtensionRule {
+	rule, err := utils.NewExtensionRule(kinds, pointers, keep, drop)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	return rule
+}
+
+func (suite *UtilsTestSuite) TestRemoveExtensionsWithRules() {
+	doc := suite.loadDoc(extensionRulesFile)
+	rules := utils.ExtensionRules{
+		// x-go-type is kept everywhere but on operations
+		suite.newExtensionRule([]string{"operation"}, nil, nil, []string{"x-go-type"}),
+		suite.newExtensionRule([]string{"operation"}, nil, []string{"x-codeSamples"}, nil),
+		// The first applying rule wins, x-internal stays on listUsers only
+		suite.newExtensionRule(nil, []string{"/paths/**/get"}, []string{"x-internal"}, nil),
+		suite.newExtensionRule([]string{"operation"}, nil, nil, []string{"x-internal"}),
+	}
+	keep, err := utils.CompileExtensionPatterns([]string{"x-go-type"})
+	assert.NoError(suite.T(), err)
+
+	utils.RemoveExtensionsWithRules(doc, rules, keep)
+
+	listUsers := doc.Paths.Find("/users").Get
+	createUser := doc.Paths.Find("/users").Post
+	user := doc.Components.Schemas["User"].Value
+	assert.Equal(suite.T(), []string{"x-codeSamples", "x-internal"}, extensionNames(listUsers.Extensions))
+	assert.Equal(suite.T(), []string{"x-codeSamples"}, extensionNames(createUser.Extensions))
+	assert.Equal(suite.T(), []string{"x-go-type"}, extensionNames(user.Extensions))
+	assert.Equal(suite.T(), []string{"x-go-type"}, extensionNames(user.Properties["name"].Value.Extensions))
+	assert.Equal(suite.T(), []string{"x-go-type"}, extensionNames(doc.Info.Extensions))
+	assert.Equal(suite.T(), []string{"x-go-type"}, extensionNames(listUsers.Parameters[0].Value.Extensions))
+}
+
+func (suite *UtilsTestSuite) TestDropExtensionsWithRules() {
+	doc := suite.loadDoc(extensionRulesFile)
+	rules := utils.ExtensionRules{
+		// x-codeSamples is kept on operations only
+		suite.newExtensionRule([]string{"operation"}, nil, []string{"x-codeSamples"}, nil),
+		suite.newExtensionRule(nil, []string{"re:^/components/"}, nil, []string{"x-go-type"}),
+	}
+	drop, err := utils.CompileExtensionPatterns([]string{"x-codeSamples"})
+	assert.NoError(suite.T(), err)
+
+	utils.DropExtensionsWithRules(doc, rules, drop)
+
+	listUsers := doc.Paths.Find("/users").Get
+	user := doc.Components.Schemas["User"].Value
+	assert.Equal(suite.T(), []string{"x-codeSamples", "x-go-type", "x-internal"}, extensionNames(listUsers.Extensions))
+	assert.Empty(suite.T(), extensionNames(user.Extensions))
+	assert.Empty(suite.T(), extensionNames(user.Properties["name"].Value.Extensions))
+	assert.Equal(suite.T(), []string{"x-go-type"}, extensionNames(doc.Info.Extensions))
+}
+
+func (suite *UtilsTestSuite) TestDropExtensionsWithRulesAtReferences() {
+	doc := suite.loadDoc(extensionRulesFile)
+	rules := utils.ExtensionRules{
+		suite.newExtensionRule(nil, []string{"/paths/**"}, nil, []string{"x-go-type"}),
+	}
+
+	utils.DropExtensionsWithRules(doc, rules, nil)
+
+	// The User schema referenced from /paths is only seen under /components
+	assert.Contains(suite.T(), doc.Info.Extensions, "x-go-type")
+	assert.NotContains(suite.T(), doc.Paths.Find("/users").Get.Extensions, "x-go-type")
+	assert.Contains(suite.T(), doc.Components.Schemas["User"].Value.Extensions, "x-go-type")
+}
+
+func (suite *UtilsTestSuite) TestRemoveExtensionsWithRulesInWebhooks() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	doc.Paths.Find("/invoices").Get.Extensions = map[string]any{"x-codeSamples": "get"}
+	paid := webhookItems(doc)["invoice.paid"].(map[string]any)
+	paid["post"].(map[string]any)["x-codeSamples"] = "post"
+	paid["x-codeSamples"] = "path item"
+	pathItems := doc.Components.Extensions["pathItems"].(map[string]any)
+	pathItems["InvoiceVoided"].(map[string]any)["put"].(map[string]any)["x-internal"] = true
+	rules := utils.ExtensionRules{
+		suite.newExtensionRule([]string{"operation"}, nil, []string{"x-codeSamples"}, nil),
+		suite.newExtensionRule(nil, []string{"/components/pathItems/*/put"}, []string{"x-internal"}, nil),
+	}
+
+	utils.RemoveExtensionsWithRules(doc, rules, nil)
+
+	// The webhook operations are ruled as operations, at their own location
+	assert.Equal(suite.T(), []string{"x-codeSamples"}, extensionNames(doc.Paths.Find("/invoices").Get.Extensions))
+	paid = webhookItems(doc)["invoice.paid"].(map[string]any)
+	assert.Equal(suite.T(), "post", paid["post"].(map[string]any)["x-codeSamples"])
+	assert.NotContains(suite.T(), paid, "x-codeSamples", "The rule does not apply to path items")
+	voided := pathItems["InvoiceVoided"].(map[string]any)
+	assert.Equal(suite.T(), true, voided["put"].(map[string]any)["x-internal"])
+}
+
+// extensionNames returns the sorted names of the extensions.
+func extensionNames(extensions map[string]any) []string {
+	return slices.Sorted(maps.Keys(extensions))
+}
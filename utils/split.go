@@ -1,21 +1,167 @@
 package utils
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
+// SplitByPath returns a document with only the paths matching the targets and
+// the components they reference. The targets map path patterns (see
+// PathPattern) to the methods to keep, all methods are kept when none is given.
+// Targets prefixed with WebhookTargetPrefix match the names of the OpenAPI 3.1
+// webhooks instead: only the targeted webhooks are kept then, all of them are
+// kept when no target names a webhook. The source document is never modified.
 func SplitByPath(doc *openapi3.T, targets map[string][]string) (*openapi3.T, error) {
+	return splitByPath(doc, targets, !hasWebhookTarget(targets))
+}
+
+// splitByPath is SplitByPath, keeping every webhook besides the targeted ones
+// when allWebhooks is true.
+func splitByPath(doc *openapi3.T, targets map[string][]string, allWebhooks bool) (*openapi3.T, error) {
 	if doc == nil {
 		return nil, ErrOpenAPINotFound
 	}
 
+	selected, unmatched, err := matchPathTargets(doc, targets)
+	if err != nil {
+		return nil, err
+	}
+	if allWebhooks {
+		for name := range webhooks(doc) {
+			selected[WebhookTargetPrefix+name] = map[string]bool{}
+		}
+	}
+	splitDoc := newSplitDocument(doc)
+	c := newComponentCollector(splitDoc, doc)
+	c.collectPaths(selected)
+	c.collectWebhooks(selected)
+	// The webhooks kept besides the targets do not count as a match
+	if len(splitDoc.Paths.Map()) == 0 && (allWebhooks || len(webhooks(splitDoc)) == 0) {
+		if len(unmatched) > 0 {
+			return nil, fmt.Errorf("%w: no path matches %s", ErrOpenAPIPathNotFound, strings.Join(unmatched, ", "))
+		}
+		return nil, ErrOpenAPIPathNotFound
+	}
+	c.collectDocumentComponents()
+	// Keep the tag definitions of the remaining operations
+	splitDoc.Tags = collectTags(doc.Tags, splitDoc)
+	// The collected items still belong to doc, the copy can be changed freely
+	// (e.g. by RemoveExtensions) without modifying the source document
+	return deepCopy(splitDoc), nil
+}
+
+// ExcludePaths returns a document without the paths matching the targets and
+// without the components only they referenced. The targets map path patterns
+// (see PathPattern) to the methods to remove, the whole path is removed when
+// no method is given. Components and tags that no path referenced in the first
+// place are kept. The source document is never modified.
+func ExcludePaths(doc *openapi3.T, targets map[string][]string) (*openapi3.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+
+	excluded, _, err := matchPathTargets(doc, targets)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[string]map[string]bool)
+	exclude := func(key string, operations map[string]*openapi3.Operation) {
+		methods, ok := excluded[key]
+		if !ok {
+			remaining[key] = map[string]bool{}
+			return
+		}
+		if len(methods) == 0 {
+			return // The whole path is excluded
+		}
+		kept := make(map[string]bool)
+		for method, operation := range operations {
+			if operation != nil && !methods[method] {
+				kept[method] = true
+			}
+		}
+		if len(kept) > 0 {
+			remaining[key] = kept
+		}
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		exclude(path, pathItem.Operations())
+	}
+	for name, operations := range webhookOperations(doc) {
+		exclude(WebhookTargetPrefix+name, operations)
+	}
+	return deepCopy(pruneDocument(doc, doc, remaining)), nil
+}
+
+// pruneDocument returns the selected paths and webhooks of doc (see
+// matchPathTargets) with the components they reference. doc is source after
+// some of its parts were removed: the components and tags that the paths and
+// webhooks of source did not reference are kept as well.
+func pruneDocument(source *openapi3.T, doc *openapi3.T, selected map[string]map[string]bool) *openapi3.T {
+	// The components referenced by the paths before the removal
+	all := allOperationTargets(source)
+	referenced := newComponentCollector(newSplitDocument(source), source)
+	referenced.collectPaths(all)
+	referenced.collectWebhooks(all)
+	referenced.collectDocumentComponents()
+
+	splitDoc := newSplitDocument(doc)
+	c := newComponentCollector(splitDoc, doc)
+	c.collectPaths(selected)
+	c.collectWebhooks(selected)
+	c.collectDocumentComponents()
+	c.collectUnreferencedComponents(referenced.splitDoc.Components)
+
+	// Keep the tag definitions that are still used or were never used
+	usedBefore := usedTags(referenced.splitDoc)
+	usedAfter := usedTags(splitDoc)
+	for _, tag := range doc.Tags {
+		if tag != nil && (usedAfter[tag.Name] || !usedBefore[tag.Name]) {
+			splitDoc.Tags = append(splitDoc.Tags, tag)
+		}
+	}
+	return splitDoc
+}
+
+// allOperationTargets selects every path and webhook of doc, in the form
+// returned by matchPathTargets.
+func allOperationTargets(doc *openapi3.T) map[string]map[string]bool {
+	all := make(map[string]map[string]bool)
+	for path := range doc.Paths.Map() {
+		all[path] = map[string]bool{}
+	}
+	for name := range webhooks(doc) {
+		all[WebhookTargetPrefix+name] = map[string]bool{}
+	}
+	return all
+}
+
+// newSplitDocument returns an empty document with the document-level fields of
+// doc, to be filled by a componentCollector.
+func newSplitDocument(doc *openapi3.T) *openapi3.T {
 	// Create a new OpenAPI document to hold the split paths
 	splitDoc := &openapi3.T{
-		OpenAPI: doc.OpenAPI,
-		Info:    doc.Info,
-		Servers: doc.Servers,
+		OpenAPI:  doc.OpenAPI,
+		Info:     doc.Info,
+		Servers:  doc.Servers,
+		Security: doc.Security,
+	}
+
+	// Keep the document-level OpenAPI 3.1 fields (e.g. jsonSchemaDialect),
+	// which the openapi3 model stores next to the extensions. The webhooks are
+	// selected like the paths, by componentCollector.collectWebhooks.
+	for key, value := range doc.Extensions {
+		if isExtensionKey(key) || key == "webhooks" {
+			continue
+		}
+		if splitDoc.Extensions == nil {
+			splitDoc.Extensions = make(map[string]any)
+		}
+		splitDoc.Extensions[key] = value
 	}
 
 	// Initialize the Paths field
@@ -29,248 +175,522 @@ func SplitByPath(doc *openapi3.T, targets map[string][]string) (*openapi3.T, err
 		Headers:         make(openapi3.Headers),
 		SecuritySchemes: make(openapi3.SecuritySchemes),
 	}
+	return splitDoc
+}
 
-	for path, pathItem := range doc.Paths.Map() {
-		if _, ok := targets[path]; !ok {
-			continue
-		}
-		op := make(map[string]bool)
-		for _, method := range targets[path] {
-			if method == "" {
-				continue
-			}
-			// Normalize method to uppercase
-			op[strings.ToUpper(method)] = true
-		}
-		var allOperations bool
-		if len(op) == 0 {
-			allOperations = true // If no specific methods are provided, include all operations
+// SplitByTag returns a document with only the operations carrying at least one
+// of the given tags, the components they reference and the definitions of the
+// given tags.
+func SplitByTag(doc *openapi3.T, tags []string) (*openapi3.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	selected := make(map[string]bool)
+	for _, tag := range tags {
+		if tag != "" {
+			selected[tag] = true
 		}
-		splitDoc.Paths.Set(path, pathItem)
-		// Collect components that are referenced in the path item
-		for method, operation := range pathItem.Operations() {
-			if operation == nil {
-				continue
-			}
-			if !allOperations && len(op) > 0 && !op[method] {
-				splitDoc.Paths.Value(path).SetOperation(method, nil) // Remove operation if not in the specified methods
-				continue                                             // Skip operations not in the specified methods
-			}
-			// Collect parameters
-			for _, param := range operation.Parameters {
-				collectPrameterComponents(splitDoc, doc, param, "")
-			}
-			// Collect request body
-			if operation.RequestBody != nil {
-				collectRequestBodyComponents(splitDoc, doc, operation.RequestBody, "")
-			}
-
-			// Collect responses
-			for _, responseRef := range operation.Responses.Map() {
-				collectResponseComponents(splitDoc, doc, responseRef, "")
-			}
+	}
 
-		}
+	targets := operationTargets(doc, func(operation *openapi3.Operation) bool {
+		return hasAnyTag(operation, selected)
+	})
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOpenAPITagNotFound, strings.Join(tags, ", "))
+	}
 
+	splitDoc, err := splitByPath(doc, targets, false)
+	if err != nil {
+		return nil, err
 	}
-	if len(splitDoc.Paths.Map()) == 0 {
-		return nil, ErrOpenAPIPathNotFound
+	// Operations may carry other tags as well, only the selected ones are kept
+	var splitTags openapi3.Tags
+	for _, tag := range splitDoc.Tags {
+		if selected[tag.Name] {
+			splitTags = append(splitTags, tag)
+		}
 	}
+	splitDoc.Tags = splitTags
 	return splitDoc, nil
 }
 
-func collectPrameterComponents(splitDoc *openapi3.T, doc *openapi3.T, param *openapi3.ParameterRef, ref string) {
-	if param == nil {
-		return
+// SplitByOperationID returns a document with only the operations with the
+// given operationIds and the components they reference.
+// Unknown operationIds are reported as ErrOpenAPIOperationNotFound.
+func SplitByOperationID(doc *openapi3.T, operationIDs []string) (*openapi3.T, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	selected := make(map[string]bool)
+	for _, id := range operationIDs {
+		if id != "" {
+			selected[id] = true
+		}
 	}
-	if param.Ref != "" {
-		typ, key := ExtractReferenceName(param.Ref)
-		switch typ {
-		case "parameters":
-			if _, exists := splitDoc.Components.Parameters[key]; exists {
-				return
-			}
-			sourceComponent := doc.Components.Parameters[key]
-			if sourceComponent == nil {
-				return
-			}
 
-			if splitDoc.Components.Parameters == nil {
-				splitDoc.Components.Parameters = make(openapi3.ParametersMap)
-			}
-			splitDoc.Components.Parameters[key] = sourceComponent
-			collectPrameterComponents(splitDoc, doc, sourceComponent, param.Ref) // Recurse with source component and original ref
-		default:
+	found := make(map[string]bool)
+	targets := operationTargets(doc, func(operation *openapi3.Operation) bool {
+		if !selected[operation.OperationID] {
+			return false
 		}
-		return
+		found[operation.OperationID] = true
+		return true
+	})
+	var unknown []string
+	for _, id := range operationIDs {
+		if id != "" && !found[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 || len(targets) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOpenAPIOperationNotFound, strings.Join(unknown, ", "))
 	}
+	return splitByPath(doc, targets, false)
+}
 
-	// Logic for handling resolved parameter 'param' (param.Ref is empty)
-	// 'ref' is the original reference string that led to this 'param' value.
-	if ref != "" {
-		originalRefType, originalRefKey := ExtractReferenceName(ref)
-		if originalRefType == "parameters" {
-			if _, exists := splitDoc.Components.Parameters[originalRefKey]; !exists {
-				if splitDoc.Components.Parameters == nil {
-					splitDoc.Components.Parameters = make(openapi3.ParametersMap)
-				}
-				splitDoc.Components.Parameters[originalRefKey] = param // Add the fully resolved parameter
+// operationTargets returns the SplitByPath targets selecting the operations,
+// of the paths and of the webhooks, for which keep returns true.
+func operationTargets(doc *openapi3.T, keep func(operation *openapi3.Operation) bool) map[string][]string {
+	targets := map[string][]string{}
+	add := func(target string, operations map[string]*openapi3.Operation) {
+		for method, operation := range operations {
+			if operation == nil || !keep(operation) {
+				continue
 			}
+			targets[target] = append(targets[target], method)
 		}
 	}
-	// If param.Value is not nil and contains an inline schema, collect it.
-	if param.Value != nil && param.Value.Schema != nil {
-		collectSchemaComponents(splitDoc, doc, param.Value.Schema)
+	for path, pathItem := range doc.Paths.Map() {
+		add(ExactPathPattern(path), pathItem.Operations())
+	}
+	for name, operations := range webhookOperations(doc) {
+		add(WebhookTargetPrefix+ExactPathPattern(name), operations)
 	}
+	return targets
 }
 
-func collectRequestBodyComponents(splitDoc *openapi3.T, doc *openapi3.T, requestBody *openapi3.RequestBodyRef, ref string) {
-	if requestBody == nil {
-		return
+func hasAnyTag(operation *openapi3.Operation, tags map[string]bool) bool {
+	for _, tag := range operation.Tags {
+		if tags[tag] {
+			return true
+		}
 	}
-	if requestBody.Ref != "" {
-		typ, key := ExtractReferenceName(requestBody.Ref)
-		switch typ {
-		case "requestBodies":
-			if _, exists := splitDoc.Components.RequestBodies[key]; exists {
-				return
+	return false
+}
+
+// collectTags returns the definitions, in document order, of the tags used by
+// the operations of doc.
+func collectTags(tags openapi3.Tags, doc *openapi3.T) openapi3.Tags {
+	used := usedTags(doc)
+	var collected openapi3.Tags
+	for _, tag := range tags {
+		if tag != nil && used[tag.Name] {
+			collected = append(collected, tag)
+		}
+	}
+	return collected
+}
+
+// usedTags returns the names of the tags used by the operations of the paths
+// and webhooks of doc.
+func usedTags(doc *openapi3.T) map[string]bool {
+	used := make(map[string]bool)
+	add := func(operations map[string]*openapi3.Operation) {
+		for _, operation := range operations {
+			if operation == nil {
+				continue
 			}
-			sourceComponent := doc.Components.RequestBodies[key]
-			if sourceComponent == nil {
-				return
+			for _, tag := range operation.Tags {
+				used[tag] = true
 			}
+		}
+	}
+	for _, pathItem := range doc.Paths.Map() {
+		add(pathItem.Operations())
+	}
+	for _, operations := range webhookOperations(doc) {
+		add(operations)
+	}
+	return used
+}
 
-			if splitDoc.Components.RequestBodies == nil {
-				splitDoc.Components.RequestBodies = make(openapi3.RequestBodies)
-			}
-			splitDoc.Components.RequestBodies[key] = sourceComponent
-			collectRequestBodyComponents(splitDoc, doc, sourceComponent, requestBody.Ref)
-		default:
+// hasWebhookTarget reports whether one of the targets is a webhook name
+// pattern (see WebhookTargetPrefix).
+func hasWebhookTarget(targets map[string][]string) bool {
+	for name := range targets {
+		if strings.HasPrefix(name, WebhookTargetPrefix) {
+			return true
 		}
-		return
 	}
+	return false
+}
 
-	if ref != "" {
-		originalRefType, originalRefKey := ExtractReferenceName(ref)
-		if originalRefType == "requestBodies" {
-			if _, exists := splitDoc.Components.RequestBodies[originalRefKey]; !exists {
-				if splitDoc.Components.RequestBodies == nil {
-					splitDoc.Components.RequestBodies = make(openapi3.RequestBodies)
+// UnmatchedPathPatterns returns the target patterns (see PathPattern) that
+// match none of the document paths (or webhooks, see WebhookTargetPrefix), in
+// sorted order.
+func UnmatchedPathPatterns(doc *openapi3.T, targets map[string][]string) ([]string, error) {
+	if doc == nil {
+		return nil, ErrOpenAPINotFound
+	}
+	_, unmatched, err := matchPathTargets(doc, targets)
+	return unmatched, err
+}
+
+// matchPathTargets resolves the target patterns against the document paths and
+// webhooks. It returns the upper-cased methods to keep for every selected path
+// (empty for all operations) and the patterns that matched nothing.
+// Selected webhooks are keyed by WebhookTargetPrefix followed by their name.
+// A path matched by several patterns keeps the methods of all of them.
+func matchPathTargets(doc *openapi3.T, targets map[string][]string) (map[string]map[string]bool, []string, error) {
+	type target struct {
+		name    string
+		webhook bool
+		pattern *PathPattern
+	}
+	compiled := make([]target, 0, len(targets))
+	for name := range targets {
+		webhookName, webhook := strings.CutPrefix(name, WebhookTargetPrefix)
+		pattern, err := CompilePathPattern(webhookName)
+		if err != nil {
+			return nil, nil, err
+		}
+		compiled = append(compiled, target{name: name, webhook: webhook, pattern: pattern})
+	}
+
+	selected := make(map[string]map[string]bool)
+	allOperations := make(map[string]bool)
+	matched := make(map[string]bool)
+	match := func(key string, name string, webhook bool) {
+		for _, t := range compiled {
+			if t.webhook != webhook || !t.pattern.Match(name) {
+				continue
+			}
+			matched[t.name] = true
+			if selected[key] == nil {
+				selected[key] = make(map[string]bool)
+			}
+			methods := 0
+			for _, method := range targets[t.name] {
+				if method == "" {
+					continue
 				}
-				splitDoc.Components.RequestBodies[originalRefKey] = requestBody
+				// Normalize method to uppercase
+				selected[key][strings.ToUpper(method)] = true
+				methods++
+			}
+			if methods == 0 {
+				allOperations[key] = true
 			}
 		}
 	}
+	for path := range doc.Paths.Map() {
+		match(path, path, false)
+	}
+	for name := range webhooks(doc) {
+		match(WebhookTargetPrefix+name, name, true)
+	}
+	for key := range allOperations {
+		selected[key] = map[string]bool{}
+	}
 
-	if requestBody.Value != nil {
-		for _, mediaItem := range requestBody.Value.Content {
-			if mediaItem.Schema != nil {
-				collectSchemaComponents(splitDoc, doc, mediaItem.Schema)
-			}
+	var unmatched []string
+	for _, t := range compiled {
+		if !matched[t.name] {
+			unmatched = append(unmatched, t.name)
 		}
 	}
+	sort.Strings(unmatched)
+	return selected, unmatched, nil
 }
 
-func collectResponseComponents(splitDoc *openapi3.T, doc *openapi3.T, response *openapi3.ResponseRef, ref string) {
-	if response == nil {
-		return
+// componentCollector copies the components referenced by the selected parts of
+// doc into splitDoc.
+type componentCollector struct {
+	splitDoc *openapi3.T
+	doc      *openapi3.T
+	// walker walks the selected parts and the collected components. References
+	// are skipped: collectRef walks the component of the source document instead.
+	walker *Walker
+}
+
+func newComponentCollector(splitDoc *openapi3.T, doc *openapi3.T) *componentCollector {
+	c := &componentCollector{
+		splitDoc: splitDoc,
+		doc:      doc,
 	}
-	if response.Ref != "" {
-		typ, key := ExtractReferenceName(response.Ref)
-		switch typ {
-		case "responses":
-			if _, exists := splitDoc.Components.Responses[key]; exists {
-				return
-			}
-			sourceComponent := doc.Components.Responses[key]
-			if sourceComponent == nil {
-				return
-			}
+	c.walker = &Walker{Refs: SkipRefs, Enter: c.enter}
+	return c
+}
+
+// walk collects the components referenced by value, located at pointer.
+func (c *componentCollector) walk(pointer string, value any) {
+	_ = c.walker.WalkValue(pointer, value) // enter never fails
+}
 
-			if splitDoc.Components.Responses == nil {
-				splitDoc.Components.Responses = make(openapi3.ResponseBodies)
+// enter collects the component a node refers to, and the components named by
+// operation security requirements, discriminator mappings and the OpenAPI 3.1
+// keywords of schemas.
+func (c *componentCollector) enter(node *Node) error {
+	if node.Ref != "" {
+		c.collectRef(node.Ref)
+	}
+	switch value := node.Value.(type) {
+	case *openapi3.Operation:
+		if value.Security != nil {
+			c.collectSecuritySchemeComponents(*value.Security)
+		}
+	case *openapi3.Schema:
+		if value.Discriminator != nil {
+			for _, mappingRef := range value.Discriminator.Mapping {
+				c.collectRef(discriminatorMappingRef(mappingRef))
 			}
-			splitDoc.Components.Responses[key] = sourceComponent
-			collectResponseComponents(splitDoc, doc, sourceComponent, response.Ref)
-		default:
 		}
-		return
+		// OpenAPI 3.1 keywords ($defs, prefixItems, if/then/else, ...) are kept as raw values
+		c.collectRawComponents(value.Extensions)
 	}
+	return nil
+}
 
-	if ref != "" {
-		originalRefType, originalRefKey := ExtractReferenceName(ref)
-		if originalRefType == "responses" {
-			if _, exists := splitDoc.Components.Responses[originalRefKey]; !exists {
-				if splitDoc.Components.Responses == nil {
-					splitDoc.Components.Responses = make(openapi3.ResponseBodies)
-				}
-				splitDoc.Components.Responses[originalRefKey] = response
+// collectPaths copies the selected paths of the source document into the
+// split document and collects the components they reference. selected maps
+// paths to the upper-cased methods to keep, all methods are kept when empty.
+func (c *componentCollector) collectPaths(selected map[string]map[string]bool) {
+	for path, pathItem := range c.doc.Paths.Map() {
+		op, ok := selected[path]
+		if !ok {
+			continue
+		}
+		pointer := "/paths/" + JSONPointerToken(path)
+		allOperations := len(op) == 0 // If no specific methods are provided, include all operations
+		// The path item is rebuilt with the selected operations only
+		splitItem := &openapi3.PathItem{
+			Extensions:  pathItem.Extensions,
+			Ref:         pathItem.Ref,
+			Summary:     pathItem.Summary,
+			Description: pathItem.Description,
+			Servers:     pathItem.Servers,
+			Parameters:  pathItem.Parameters,
+		}
+		pruned := false
+		// Collect components that are referenced in the path item
+		for method, operation := range pathItem.Operations() {
+			if operation == nil {
+				continue
+			}
+			if !allOperations && !op[method] {
+				pruned = true
+				continue // Skip operations not in the specified methods
+			}
+			splitItem.SetOperation(method, operation)
+			c.walk(pointer+"/"+strings.ToLower(method), operation)
+		}
+		// Collect path-level parameters, shared by all the operations
+		for i, param := range splitItem.Parameters {
+			c.walk(pointer+"/parameters/"+strconv.Itoa(i), param)
+		}
+		if splitItem.Ref != "" {
+			if pruned {
+				// A reference would bring back every operation, so the resolved path item is inlined
+				splitItem.Ref = ""
+			} else {
+				// Collect the path item itself when it is an OpenAPI 3.1 "#/components/pathItems" reference
+				c.collectRef(pathItem.Ref)
 			}
 		}
+		c.splitDoc.Paths.Set(path, splitItem)
 	}
+}
 
-	if response.Value != nil {
-		for _, mediaItem := range response.Value.Content {
-			if mediaItem.Schema != nil {
-				collectSchemaComponents(splitDoc, doc, mediaItem.Schema)
-			}
+// collectDocumentComponents collects the components referenced from the
+// document level: the global security requirements and the OpenAPI 3.1 fields.
+func (c *componentCollector) collectDocumentComponents() {
+	// Collect the security schemes of the global security requirements
+	c.collectSecuritySchemeComponents(c.doc.Security)
+	// Collect components referenced from the document-level OpenAPI 3.1 fields
+	c.collectRawComponents(c.splitDoc.Extensions)
+}
+
+// collectUnreferencedComponents collects the components of the source
+// document that are missing from referenced, with everything they reference.
+func (c *componentCollector) collectUnreferencedComponents(referenced *openapi3.Components) {
+	source := c.doc.Components
+	if source == nil {
+		return
+	}
+	collect := func(kind string, name string, isReferenced bool) {
+		if !isReferenced {
+			c.collectRef("#/components/" + kind + "/" + name)
 		}
 	}
+	for name := range source.Schemas {
+		_, ok := referenced.Schemas[name]
+		collect("schemas", name, ok)
+	}
+	for name := range source.Parameters {
+		_, ok := referenced.Parameters[name]
+		collect("parameters", name, ok)
+	}
+	for name := range source.RequestBodies {
+		_, ok := referenced.RequestBodies[name]
+		collect("requestBodies", name, ok)
+	}
+	for name := range source.Responses {
+		_, ok := referenced.Responses[name]
+		collect("responses", name, ok)
+	}
+	for name := range source.Headers {
+		_, ok := referenced.Headers[name]
+		collect("headers", name, ok)
+	}
+	for name := range source.Examples {
+		_, ok := referenced.Examples[name]
+		collect("examples", name, ok)
+	}
+	for name := range source.Links {
+		_, ok := referenced.Links[name]
+		collect("links", name, ok)
+	}
+	for name := range source.Callbacks {
+		_, ok := referenced.Callbacks[name]
+		collect("callbacks", name, ok)
+	}
+	for name := range source.SecuritySchemes {
+		_, ok := referenced.SecuritySchemes[name]
+		collect("securitySchemes", name, ok)
+	}
+	sourcePathItems, _ := source.Extensions["pathItems"].(map[string]any)
+	referencedPathItems, _ := referenced.Extensions["pathItems"].(map[string]any)
+	for name := range sourcePathItems {
+		_, ok := referencedPathItems[name]
+		collect("pathItems", name, ok)
+	}
 }
 
-func collectSchemaComponents(splitDoc *openapi3.T, doc *openapi3.T, schemaRefToProcess *openapi3.SchemaRef) {
-	if schemaRefToProcess == nil {
+// collectSecuritySchemeComponents collects the security schemes named by the
+// security requirements. The requirements themselves (with their scopes) are
+// kept as they are on the operations and the document.
+func (c *componentCollector) collectSecuritySchemeComponents(requirements openapi3.SecurityRequirements) {
+	for _, requirement := range requirements {
+		for name := range requirement {
+			c.collectRef("#/components/securitySchemes/" + name)
+		}
+	}
+}
+
+// collectRef copies the component a reference points to from the source
+// document into the split document, and collects the components it references
+// in turn. Components that are missing or already collected are skipped.
+func (c *componentCollector) collectRef(ref string) {
+	if c.doc.Components == nil {
 		return
 	}
+	typ, key := ExtractReferenceName(ref)
+	source, split := c.doc.Components, c.splitDoc.Components
+	var (
+		component any
+		ok        bool
+	)
+	switch typ {
+	case "schemas":
+		component, ok = copyComponent(source.Schemas, &split.Schemas, key)
+	case "parameters":
+		component, ok = copyComponent(source.Parameters, &split.Parameters, key)
+	case "requestBodies":
+		component, ok = copyComponent(source.RequestBodies, &split.RequestBodies, key)
+	case "responses":
+		component, ok = copyComponent(source.Responses, &split.Responses, key)
+	case "headers":
+		component, ok = copyComponent(source.Headers, &split.Headers, key)
+	case "examples":
+		component, ok = copyComponent(source.Examples, &split.Examples, key)
+	case "links":
+		component, ok = copyComponent(source.Links, &split.Links, key)
+	case "callbacks":
+		component, ok = copyComponent(source.Callbacks, &split.Callbacks, key)
+	case "securitySchemes":
+		component, ok = copyComponent(source.SecuritySchemes, &split.SecuritySchemes, key)
+	case "pathItems":
+		c.collectPathItemComponents(key)
+	}
+	if ok {
+		c.walk("/components/"+typ+"/"+JSONPointerToken(key), component)
+	}
+}
 
-	var currentSchemaValue *openapi3.Schema
+// copyComponent copies the component named key from source into split, and
+// reports whether it was copied: it is not when it is missing from source or
+// already in split.
+func copyComponent[M ~map[string]V, V comparable](source M, split *M, key string) (V, bool) {
+	var zero V
+	component := source[key]
+	if component == zero {
+		return zero, false
+	}
+	if _, exists := (*split)[key]; exists {
+		return zero, false
+	}
+	if *split == nil {
+		*split = make(M)
+	}
+	(*split)[key] = component
+	return component, true
+}
 
-	if schemaRefToProcess.Ref != "" { // It's a reference e.g. "#/components/schemas/MySchema"
-		refType, refKey := ExtractReferenceName(schemaRefToProcess.Ref)
-		if refType != "schemas" {
-			return // Not a schema component reference
-		}
+// discriminatorMappingRef returns the reference of a discriminator mapping
+// value, which is either a reference or the bare name of a schema component.
+func discriminatorMappingRef(value string) string {
+	if strings.Contains(value, "/") {
+		return value
+	}
+	return "#/components/schemas/" + value
+}
 
-		// If already added, get its Value to process children. Avoids re-adding/overwriting.
-		if existingRef, exists := splitDoc.Components.Schemas[refKey]; exists {
-			currentSchemaValue = existingRef.Value
-		} else {
-			sourceComponent := doc.Components.Schemas[refKey]
-			if sourceComponent == nil {
-				return // Source component not found
+// collectRawComponents collects the components referenced by "$ref" anywhere in
+// a raw value, i.e. in the parts of the document the openapi3 model keeps as
+// plain maps and slices (OpenAPI 3.1 keywords such as webhooks or $defs).
+func (c *componentCollector) collectRawComponents(value any) {
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			c.collectRawComponents(item)
+		}
+	case map[string]any:
+		for key, item := range value {
+			if key != "$ref" {
+				c.collectRawComponents(item)
+				continue
 			}
-
-			if splitDoc.Components.Schemas == nil {
-				splitDoc.Components.Schemas = make(openapi3.Schemas)
+			if ref, ok := item.(string); ok {
+				c.collectRef(ref)
 			}
-			splitDoc.Components.Schemas[refKey] = sourceComponent // Add the component (SchemaRef) from source doc
-			currentSchemaValue = sourceComponent.Value            // Process the value of this newly added component
 		}
-	} else {
-		// It's an inline schema or a pre-resolved schema value (e.g. from a previous step)
-		currentSchemaValue = schemaRefToProcess.Value
-	}
-
-	if currentSchemaValue == nil {
-		return // No actual schema content to process
 	}
+}
 
-	// Process children of currentSchemaValue
-	for _, propertySchemaRef := range currentSchemaValue.Properties {
-		collectSchemaComponents(splitDoc, doc, propertySchemaRef)
+// collectPathItemComponents collects an OpenAPI 3.1 "#/components/pathItems"
+// entry, which the openapi3 model keeps in the components extensions.
+func (c *componentCollector) collectPathItemComponents(key string) {
+	if c.doc.Components == nil {
+		return
 	}
-	if currentSchemaValue.Items != nil {
-		collectSchemaComponents(splitDoc, doc, currentSchemaValue.Items)
+	sourcePathItems, _ := c.doc.Components.Extensions["pathItems"].(map[string]any)
+	sourceComponent, ok := sourcePathItems[key]
+	if !ok {
+		return
 	}
-	for _, allOfSchemaRef := range currentSchemaValue.AllOf {
-		collectSchemaComponents(splitDoc, doc, allOfSchemaRef)
+	if c.splitDoc.Components.Extensions == nil {
+		c.splitDoc.Components.Extensions = make(map[string]any)
 	}
-	for _, oneOfSchemaRef := range currentSchemaValue.OneOf {
-		collectSchemaComponents(splitDoc, doc, oneOfSchemaRef)
+	pathItems, _ := c.splitDoc.Components.Extensions["pathItems"].(map[string]any)
+	if pathItems == nil {
+		pathItems = make(map[string]any)
+		c.splitDoc.Components.Extensions["pathItems"] = pathItems
 	}
-	for _, anyOfSchemaRef := range currentSchemaValue.AnyOf {
-		collectSchemaComponents(splitDoc, doc, anyOfSchemaRef)
+	if _, exists := pathItems[key]; exists {
+		return
 	}
+	pathItems[key] = sourceComponent
+	c.collectRawComponents(sourceComponent)
 }
 
 func ExtractReferenceName(ref string) (string, string) {
@@ -286,6 +706,11 @@ func ExtractReferenceName(ref string) (string, string) {
 	if len(parts) < 2 {
 		return "", "" // Or handle as an error, or return the single part as key if appropriate
 	}
+	// A reference into a component (e.g. "#/components/schemas/Pet/$defs/Tag")
+	// resolves to the enclosing component.
+	if len(parts) > 4 && parts[0] == "#" && parts[1] == "components" {
+		return parts[2], parts[3]
+	}
 	// The component type is the second to last part, and the key is the last part.
 	return parts[len(parts)-2], parts[len(parts)-1]
 }
@@ -81,6 +81,12 @@ func TestExtractReferenceName(t *testing.T) {
 			expectedType: "schemas",
 			expectedKey:  "User",
 		},
+		{
+			name:         "ref into a component",
+			ref:          "#/components/schemas/Pet/$defs/Tag",
+			expectedType: "schemas",
+			expectedKey:  "Pet",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -201,3 +207,492 @@ func (suite *UtilsTestSuite) TestSplitByPathNilDocument() {
 	assert.Error(suite.T(), err, "Expected an error when splitting with a nil document")
 	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound, "Expected ErrOpenAPINotFound")
 }
+
+func (suite *UtilsTestSuite) TestSplitByPath_GlobPattern() {
+	doc := suite.loadTestAPIDoc()
+	output, err := utils.SplitByPath(doc, map[string][]string{
+		"/*-path": {},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output.Paths.Map(), "/complex-path")
+	assert.Contains(suite.T(), output.Paths.Map(), "/simple-path")
+	assert.NotContains(suite.T(), output.Paths.Map(), "/path-with-allof-schema")
+	assert.Contains(suite.T(), output.Components.Schemas, "MainSchema")
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_RegexPatternWithMethods() {
+	doc := suite.loadTestAPIDoc()
+	output, err := utils.SplitByPath(doc, map[string][]string{
+		"re:^/path-with-": {"get"},
+		"re:allof":        {"post"},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), output.Paths.Map(), 1)
+	pathItem := output.Paths.Value("/path-with-allof-schema")
+	if assert.NotNil(suite.T(), pathItem) {
+		// The methods of every matching pattern are kept
+		assert.NotNil(suite.T(), pathItem.Post)
+	}
+	assert.Contains(suite.T(), output.Components.Schemas, "AllOfSchema")
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_UnmatchedPatterns() {
+	doc := suite.loadTestAPIDoc()
+	targets := map[string][]string{
+		"/simple-path": {},
+		"/users/**":    {},
+		"re:^/admin":   {},
+	}
+	output, err := utils.SplitByPath(doc, targets)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), output.Paths.Map(), "/simple-path")
+
+	unmatched, err := utils.UnmatchedPathPatterns(doc, targets)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"/users/**", "re:^/admin"}, unmatched)
+
+	_, err = utils.SplitByPath(doc, map[string][]string{"/users/**": {}})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIPathNotFound)
+	assert.Contains(suite.T(), err.Error(), "/users/**")
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_InvalidPattern() {
+	doc := suite.loadTestAPIDoc()
+	_, err := utils.SplitByPath(doc, map[string][]string{"re:(": {}})
+	assert.ErrorIs(suite.T(), err, utils.ErrInvalidPathPattern)
+}
+
+func tagNames(tags openapi3.Tags) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func (suite *UtilsTestSuite) TestSplitByTag() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.SplitByTag(doc, []string{"billing"})
+	assert.NoError(suite.T(), err)
+
+	invoices := output.Paths.Value("/invoices")
+	if assert.NotNil(suite.T(), invoices) {
+		assert.NotNil(suite.T(), invoices.Get)
+		assert.Nil(suite.T(), invoices.Post, "createInvoice is not tagged billing")
+	}
+	payments := output.Paths.Value("/payments")
+	if assert.NotNil(suite.T(), payments) {
+		assert.NotNil(suite.T(), payments.Post)
+	}
+	assert.Nil(suite.T(), output.Paths.Value("/users"))
+
+	assert.Contains(suite.T(), output.Components.Schemas, "Invoice")
+	assert.Contains(suite.T(), output.Components.Schemas, "Money", "Missing Money (via Invoice)")
+	assert.Contains(suite.T(), output.Components.Schemas, "Payment", "Missing Payment (via the Payment response)")
+	assert.Contains(suite.T(), output.Components.Parameters, "UserId")
+	assert.Contains(suite.T(), output.Components.Responses, "Payment")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.RequestBodies, "InvoiceBody")
+
+	// createPayment is also tagged users, but only the selected tags are kept
+	assert.Equal(suite.T(), []string{"billing"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestSplitByTag_MultipleTags() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.SplitByTag(doc, []string{"invoices", "billing"})
+	assert.NoError(suite.T(), err)
+
+	invoices := output.Paths.Value("/invoices")
+	if assert.NotNil(suite.T(), invoices) {
+		assert.NotNil(suite.T(), invoices.Get)
+		assert.NotNil(suite.T(), invoices.Post)
+	}
+	assert.Contains(suite.T(), output.Components.RequestBodies, "InvoiceBody")
+	assert.Equal(suite.T(), []string{"billing", "invoices"}, tagNames(output.Tags), "Tags keep the document order")
+}
+
+func (suite *UtilsTestSuite) TestSplitByTag_NotFound() {
+	doc := suite.loadDoc(splitTagsFile)
+	_, err := utils.SplitByTag(doc, []string{"shipping"})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPITagNotFound)
+	assert.Contains(suite.T(), err.Error(), "shipping")
+
+	_, err = utils.SplitByTag(nil, []string{"billing"})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_KeepsUsedTags() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"/users": {}})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"users"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestSplitByOperationID() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.SplitByOperationID(doc, []string{"createInvoice", "listUsers"})
+	assert.NoError(suite.T(), err)
+
+	invoices := output.Paths.Value("/invoices")
+	if assert.NotNil(suite.T(), invoices) {
+		assert.Nil(suite.T(), invoices.Get, "listInvoices was not selected")
+		assert.NotNil(suite.T(), invoices.Post)
+	}
+	assert.NotNil(suite.T(), output.Paths.Value("/users"))
+	assert.Nil(suite.T(), output.Paths.Value("/payments"))
+
+	assert.Contains(suite.T(), output.Components.RequestBodies, "InvoiceBody")
+	assert.Contains(suite.T(), output.Components.Schemas, "Invoice", "Missing Invoice (via InvoiceBody)")
+	assert.Contains(suite.T(), output.Components.Schemas, "Money", "Missing Money (via Invoice)")
+	assert.Contains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.Schemas, "Payment")
+	assert.Equal(suite.T(), []string{"invoices", "users"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestSplitByOperationID_UnknownIDs() {
+	doc := suite.loadDoc(splitTagsFile)
+	_, err := utils.SplitByOperationID(doc, []string{"listInvoices", "deleteInvoice", "createUser"})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIOperationNotFound)
+	assert.Contains(suite.T(), err.Error(), "deleteInvoice, createUser")
+	assert.NotContains(suite.T(), err.Error(), "listInvoices")
+
+	_, err = utils.SplitByOperationID(doc, []string{})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIOperationNotFound)
+
+	_, err = utils.SplitByOperationID(nil, []string{"listInvoices"})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
+
+func (suite *UtilsTestSuite) TestSplitByOperationID_SecuritySchemes() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.SplitByOperationID(doc, []string{"createInvoice"})
+	assert.NoError(suite.T(), err)
+
+	// The global requirement is kept with the schemes of both requirements
+	assert.Equal(suite.T(), doc.Security, output.Security)
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "apiKey")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "oauth2")
+	assert.NotContains(suite.T(), output.Components.SecuritySchemes, "basicAuth")
+
+	// Scopes are kept intact
+	operation := output.Paths.Value("/invoices").Post
+	if assert.NotNil(suite.T(), operation.Security) {
+		assert.Equal(suite.T(), openapi3.SecurityRequirements{{"oauth2": {"invoices:write"}}}, *operation.Security)
+	}
+}
+
+func (suite *UtilsTestSuite) TestSplitByOperationID_PublicOperationSecurity() {
+	doc := suite.loadDoc(splitTagsFile)
+	doc.Security = nil
+	output, err := utils.SplitByOperationID(doc, []string{"listUsers"})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), output.Security)
+	assert.Empty(suite.T(), output.Components.SecuritySchemes)
+	// The explicitly empty requirement (public operation) is kept
+	operation := output.Paths.Value("/users").Get
+	if assert.NotNil(suite.T(), operation.Security) {
+		assert.Empty(suite.T(), *operation.Security)
+	}
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_CyclicSchemas() {
+	testCases := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "self-referencing schema",
+			path:     "/tree",
+			expected: []string{"Node"},
+		},
+		{
+			name:     "mutually recursive schemas",
+			path:     "/org-chart",
+			expected: []string{"Department", "Employee"},
+		},
+		{
+			name:     "recursion through allOf",
+			path:     "/threads",
+			expected: []string{"Comment", "Reply"},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			doc := suite.loadDoc(splitCyclicFile)
+			output, err := utils.SplitByPath(doc, map[string][]string{tc.path: {}})
+			assert.NoError(suite.T(), err)
+
+			names := make([]string, 0, len(output.Components.Schemas))
+			for name := range output.Components.Schemas {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(suite.T(), tc.expected, names)
+
+			// The split document can be marshaled and loaded again
+			data, err := output.MarshalJSON()
+			assert.NoError(suite.T(), err)
+			_, err = openapi3.NewLoader().LoadFromData(data)
+			assert.NoError(suite.T(), err)
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_AllCyclicPaths() {
+	doc := suite.loadDoc(splitCyclicFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"/**": {}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), output.Components.Schemas, 5)
+	assert.NotContains(suite.T(), output.Components.Schemas, "Unused")
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_SchemaKeywords() {
+	testCases := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{
+			name:     "discriminator mapping targets",
+			path:     "/pets",
+			expected: []string{"Pet", "Dog", "Cat", "Owner"},
+		},
+		{
+			name:     "additionalProperties and parameter content",
+			path:     "/labels",
+			expected: []string{"Labels", "Label", "LabelFilter"},
+		},
+		{
+			name:     "not",
+			path:     "/not-a-cat",
+			expected: []string{"Cat", "Owner"},
+		},
+	}
+
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			loader := openapi3.NewLoader()
+			doc, err := loader.LoadFromData(splitSchemasFile)
+			assert.NoError(suite.T(), err)
+
+			output, err := utils.SplitByPath(doc, map[string][]string{tc.path: {}})
+			assert.NoError(suite.T(), err)
+
+			names := make([]string, 0, len(output.Components.Schemas))
+			for name := range output.Components.Schemas {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(suite.T(), tc.expected, names)
+		})
+	}
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_AllComponentKinds() {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(splitComponentsFile)
+	assert.NoError(suite.T(), err)
+
+	output, err := utils.SplitByPath(doc, map[string][]string{"/subscriptions": {}})
+	assert.NoError(suite.T(), err)
+	components := output.Components
+
+	assert.Contains(suite.T(), components.Examples, "BasicSubscription")
+	assert.NotContains(suite.T(), components.Examples, "UnusedExample")
+	assert.Contains(suite.T(), components.Headers, "RateLimit")
+	assert.NotContains(suite.T(), components.Headers, "UnusedHeader")
+	assert.Contains(suite.T(), components.Links, "GetSubscription")
+	assert.NotContains(suite.T(), components.Links, "UnusedLink")
+	assert.Contains(suite.T(), components.Callbacks, "SubscriptionEvent")
+	assert.NotContains(suite.T(), components.Callbacks, "UnusedCallback")
+
+	schemas := make([]string, 0, len(components.Schemas))
+	for name := range components.Schemas {
+		schemas = append(schemas, name)
+	}
+	// Limit via the RateLimit header, Url via the inline Location header,
+	// Event via the referenced callback and Cancellation via the inline one
+	assert.ElementsMatch(suite.T(), []string{"Subscription", "Url", "Limit", "Event", "Cancellation"}, schemas)
+
+	// The split document has no dangling references
+	data, err := output.MarshalJSON()
+	assert.NoError(suite.T(), err)
+	reloaded, err := openapi3.NewLoader().LoadFromData(data)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), reloaded.Validate(loader.Context))
+}
+
+func (suite *UtilsTestSuite) loadPathItemsAPIDoc() *openapi3.T {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromFile("testdata/split_path_items/api.yaml")
+	if err != nil {
+		suite.T().Fatalf("Failed to load data from testdata/split_path_items/api.yaml: %v", err)
+	}
+	return doc
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_PathLevelFields() {
+	doc := suite.loadPathItemsAPIDoc()
+	output, err := utils.SplitByPath(doc, map[string][]string{"/users/{userId}": {"delete"}})
+	assert.NoError(suite.T(), err)
+
+	pathItem := output.Paths.Value("/users/{userId}")
+	if assert.NotNil(suite.T(), pathItem) {
+		assert.Nil(suite.T(), pathItem.Get, "GET was not selected")
+		assert.NotNil(suite.T(), pathItem.Delete)
+		assert.Equal(suite.T(), "A single user", pathItem.Summary)
+		assert.Equal(suite.T(), "Operations on a single user", pathItem.Description)
+		if assert.Len(suite.T(), pathItem.Servers, 1) {
+			assert.Equal(suite.T(), "https://users.example.com", pathItem.Servers[0].URL)
+		}
+		assert.Len(suite.T(), pathItem.Parameters, 2)
+	}
+	// Parameters declared only at the path level are collected with their schemas
+	assert.Contains(suite.T(), output.Components.Parameters, "UserId")
+	assert.Contains(suite.T(), output.Components.Schemas, "UserIdFormat", "Missing UserIdFormat (via the UserId parameter)")
+	assert.Contains(suite.T(), output.Components.Schemas, "Tenant", "Missing Tenant (via the inline path parameter)")
+	assert.NotContains(suite.T(), output.Components.Schemas, "Account")
+
+	// Pruning methods leaves the source document untouched
+	assert.NotNil(suite.T(), doc.Paths.Value("/users/{userId}").Get)
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_RefPathItem() {
+	doc := suite.loadPathItemsAPIDoc()
+
+	output, err := utils.SplitByPath(doc, map[string][]string{"/accounts": {}})
+	assert.NoError(suite.T(), err)
+	pathItem := output.Paths.Value("/accounts")
+	if assert.NotNil(suite.T(), pathItem) {
+		assert.Equal(suite.T(), "./accounts.yaml", pathItem.Ref, "The reference is kept when every method is selected")
+	}
+	assert.Contains(suite.T(), output.Components.Schemas, "Account")
+
+	output, err = utils.SplitByPath(doc, map[string][]string{"/accounts": {"post"}})
+	assert.NoError(suite.T(), err)
+	pathItem = output.Paths.Value("/accounts")
+	if assert.NotNil(suite.T(), pathItem) {
+		assert.Empty(suite.T(), pathItem.Ref, "The path item is inlined when methods are pruned")
+		assert.Nil(suite.T(), pathItem.Get)
+		assert.NotNil(suite.T(), pathItem.Post)
+	}
+	assert.NotContains(suite.T(), output.Components.Schemas, "Account")
+	assert.Equal(suite.T(), "./accounts.yaml", doc.Paths.Value("/accounts").Ref)
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_RepeatedSplits() {
+	doc := suite.loadDoc(splitTagsFile)
+	before, err := doc.MarshalJSON()
+	assert.NoError(suite.T(), err)
+
+	testCases := []struct {
+		methods      []string
+		expectedGet  bool
+		expectedPost bool
+	}{
+		{methods: []string{"get"}, expectedGet: true},
+		{methods: []string{"post"}, expectedPost: true},
+		{methods: []string{}, expectedGet: true, expectedPost: true},
+		{methods: []string{"get"}, expectedGet: true},
+	}
+	var outputs []*openapi3.T
+	for _, tc := range testCases {
+		output, err := utils.SplitByPath(doc, map[string][]string{"/invoices": tc.methods})
+		assert.NoError(suite.T(), err)
+		pathItem := output.Paths.Value("/invoices")
+		if assert.NotNil(suite.T(), pathItem, "methods %v", tc.methods) {
+			assert.Equal(suite.T(), tc.expectedGet, pathItem.Get != nil, "methods %v", tc.methods)
+			assert.Equal(suite.T(), tc.expectedPost, pathItem.Post != nil, "methods %v", tc.methods)
+		}
+		outputs = append(outputs, output)
+	}
+
+	// Changing the split documents leaves the source and the other splits untouched
+	utils.RemoveExtensions(outputs[0], nil)
+	outputs[1].Paths.Value("/invoices").Post.Summary = "changed"
+	outputs[1].Components.Schemas["Invoice"].Value.Properties["id"].Value.Description = "changed"
+	outputs[3].Paths.Value("/invoices").Get.Responses.Set("418", &openapi3.ResponseRef{Value: openapi3.NewResponse()})
+
+	after, err := doc.MarshalJSON()
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), string(before), string(after), "The source document was modified")
+	assert.Contains(suite.T(), outputs[2].Components.Schemas["Invoice"].Value.Extensions, "x-internal")
+	assert.Empty(suite.T(), outputs[2].Paths.Value("/invoices").Post.Summary)
+	assert.Empty(suite.T(), outputs[2].Components.Schemas["Invoice"].Value.Properties["id"].Value.Description)
+	assert.Nil(suite.T(), outputs[2].Paths.Value("/invoices").Get.Responses.Value("418"))
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_CopyKeepsSharedSchemas() {
+	doc := suite.loadDoc(splitCyclicFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"/tree": {}})
+	assert.NoError(suite.T(), err)
+
+	node := output.Components.Schemas["Node"].Value
+	assert.NotSame(suite.T(), doc.Components.Schemas["Node"].Value, node)
+	// The recursive references still point to the (copied) component
+	assert.Same(suite.T(), node, node.Properties["parent"].Value)
+	assert.Same(suite.T(), node, node.Properties["children"].Value.Items.Value)
+}
+
+func (suite *UtilsTestSuite) TestExcludePaths() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.ExcludePaths(doc, map[string][]string{"/payments": {}})
+	assert.NoError(suite.T(), err)
+
+	assert.Nil(suite.T(), output.Paths.Value("/payments"))
+	assert.NotNil(suite.T(), output.Paths.Value("/invoices"))
+	assert.NotNil(suite.T(), output.Paths.Value("/users"))
+
+	// Only referenced by the excluded path
+	assert.NotContains(suite.T(), output.Components.Responses, "Payment")
+	assert.NotContains(suite.T(), output.Components.Parameters, "UserId")
+	// Still referenced by the remaining paths
+	assert.Contains(suite.T(), output.Components.Schemas, "Invoice")
+	assert.Contains(suite.T(), output.Components.Schemas, "Money")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "apiKey")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "oauth2")
+	// Never referenced by a path, kept with what they reference
+	assert.Contains(suite.T(), output.Components.Schemas, "LegacyPayment")
+	assert.Contains(suite.T(), output.Components.Schemas, "Payment", "Missing Payment (via LegacyPayment)")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "basicAuth")
+
+	assert.Equal(suite.T(), []string{"billing", "invoices", "users"}, tagNames(output.Tags))
+	assert.NotNil(suite.T(), doc.Paths.Value("/payments"), "The source document was modified")
+}
+
+func (suite *UtilsTestSuite) TestExcludePaths_PatternsAndMethods() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.ExcludePaths(doc, map[string][]string{
+		"re:^/(users|payments)$": {},
+		"/invoices":              {"post"},
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.Len(suite.T(), output.Paths.Map(), 1)
+	invoices := output.Paths.Value("/invoices")
+	if assert.NotNil(suite.T(), invoices) {
+		assert.NotNil(suite.T(), invoices.Get)
+		assert.Nil(suite.T(), invoices.Post)
+	}
+	assert.NotContains(suite.T(), output.Components.RequestBodies, "InvoiceBody")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.SecuritySchemes, "oauth2")
+	// The users tag is no longer used, the invoices tag still is
+	assert.Equal(suite.T(), []string{"billing", "invoices"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestExcludePaths_Everything() {
+	doc := suite.loadDoc(splitTagsFile)
+	output, err := utils.ExcludePaths(doc, map[string][]string{"/**": {}})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), output.Paths.Map())
+	assert.Empty(suite.T(), output.Tags)
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "apiKey", "The global requirement still references apiKey")
+	assert.Contains(suite.T(), output.Components.Schemas, "LegacyPayment")
+	assert.NotContains(suite.T(), output.Components.Schemas, "Invoice")
+
+	_, err = utils.ExcludePaths(nil, map[string][]string{"/**": {}})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPINotFound)
+}
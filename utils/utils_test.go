@@ -4,6 +4,7 @@ import (
 	_ "embed"
 	"testing"
 
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/suite"
 )
@@ -11,12 +12,51 @@ import (
 //go:embed testdata/api.yaml
 var file []byte
 
+//go:embed testdata/rename_extensions_api.yaml
+var renameExtensionsFile []byte
+
 //go:embed testdata/remove_extensions_api.yaml
 var removeExtensionsFile []byte
 
+//go:embed testdata/remove_all_extensions_api.yaml
+var removeAllExtensionsFile []byte
+
 //go:embed testdata/split_api.yaml
 var splitFile []byte
 
+//go:embed testdata/split_tags_api.yaml
+var splitTagsFile []byte
+
+//go:embed testdata/split_cyclic_api.yaml
+var splitCyclicFile []byte
+
+//go:embed testdata/split_schemas_api.yaml
+var splitSchemasFile []byte
+
+//go:embed testdata/split_components_api.yaml
+var splitComponentsFile []byte
+
+//go:embed testdata/split_webhooks_api.yaml
+var splitWebhooksFile []byte
+
+//go:embed testdata/deprecated_api.yaml
+var deprecatedFile []byte
+
+//go:embed testdata/deprecated_webhooks_api.yaml
+var deprecatedWebhooksFile []byte
+
+//go:embed testdata/extension_rules_api.yaml
+var extensionRulesFile []byte
+
+//go:embed testdata/filter_extensions_api.yaml
+var filterExtensionsFile []byte
+
+//go:embed testdata/walk_api.yaml
+var walkFile []byte
+
+//go:embed testdata/openapi31_api.yaml
+var openAPI31File []byte
+
 type UtilsTestSuite struct {
 	suite.Suite
 	Doc *openapi3.T
@@ -31,6 +71,16 @@ func (suite *UtilsTestSuite) SetupTest() {
 	suite.Doc = doc
 }
 
+// loadDoc loads an embedded test document with LoadDocument, so OpenAPI 3.1
+// fixtures are normalized like the documents given to the command.
+func (suite *UtilsTestSuite) loadDoc(data []byte) *openapi3.T {
+	doc, err := utils.LoadDocument(nil, data, nil)
+	if err != nil {
+		suite.T().Fatal(err)
+	}
+	return doc
+}
+
 func TestUtilsTestSuite(t *testing.T) {
 	suite.Run(t, new(UtilsTestSuite))
 }
@@ -0,0 +1,824 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// NodeKind is the kind of OpenAPI object a Node holds.
+type NodeKind string
+
+// The kinds of nodes visited by a Walker, with the type of their Node.Value.
+const (
+	KindDocument       NodeKind = "document"       // *openapi3.T
+	KindInfo           NodeKind = "info"           // *openapi3.Info
+	KindContact        NodeKind = "contact"        // *openapi3.Contact
+	KindLicense        NodeKind = "license"        // *openapi3.License
+	KindServer         NodeKind = "server"         // *openapi3.Server
+	KindServerVariable NodeKind = "serverVariable" // *openapi3.ServerVariable
+	KindTag            NodeKind = "tag"            // *openapi3.Tag
+	KindExternalDocs   NodeKind = "externalDocs"   // *openapi3.ExternalDocs
+	KindComponents     NodeKind = "components"     // *openapi3.Components
+	KindPaths          NodeKind = "paths"          // *openapi3.Paths
+	KindPathItem       NodeKind = "pathItem"       // *openapi3.PathItem
+	KindOperation      NodeKind = "operation"      // *openapi3.Operation
+	KindParameter      NodeKind = "parameter"      // *openapi3.Parameter
+	KindRequestBody    NodeKind = "requestBody"    // *openapi3.RequestBody
+	KindResponses      NodeKind = "responses"      // *openapi3.Responses
+	KindResponse       NodeKind = "response"       // *openapi3.Response
+	KindHeader         NodeKind = "header"         // *openapi3.Header
+	KindMediaType      NodeKind = "mediaType"      // *openapi3.MediaType
+	KindEncoding       NodeKind = "encoding"       // *openapi3.Encoding
+	KindExample        NodeKind = "example"        // *openapi3.Example
+	KindLink           NodeKind = "link"           // *openapi3.Link
+	KindCallback       NodeKind = "callback"       // *openapi3.Callback
+	KindSchema         NodeKind = "schema"         // *openapi3.Schema
+	KindDiscriminator  NodeKind = "discriminator"  // *openapi3.Discriminator
+	KindXML            NodeKind = "xml"            // *openapi3.XML
+	KindSecurityScheme NodeKind = "securityScheme" // *openapi3.SecurityScheme
+	KindOAuthFlows     NodeKind = "oauthFlows"     // *openapi3.OAuthFlows
+	KindOAuthFlow      NodeKind = "oauthFlow"      // *openapi3.OAuthFlow
+)
+
+// nodeKinds holds the kinds of nodes visited by a Walker.
+var nodeKinds = map[NodeKind]struct{}{
+	KindDocument: {}, KindInfo: {}, KindContact: {}, KindLicense: {}, KindServer: {},
+	KindServerVariable: {}, KindTag: {}, KindExternalDocs: {}, KindComponents: {},
+	KindPaths: {}, KindPathItem: {}, KindOperation: {}, KindParameter: {},
+	KindRequestBody: {}, KindResponses: {}, KindResponse: {}, KindHeader: {},
+	KindMediaType: {}, KindEncoding: {}, KindExample: {}, KindLink: {},
+	KindCallback: {}, KindSchema: {}, KindDiscriminator: {}, KindXML: {},
+	KindSecurityScheme: {}, KindOAuthFlows: {}, KindOAuthFlow: {},
+}
+
+// Node is an object of the document visited by a Walker.
+type Node struct {
+	Kind NodeKind
+	// Pointer is the JSON pointer of the node in the document, e.g.
+	// "/paths/~1users/get". A node reached through a reference has the
+	// location of the reference.
+	Pointer string
+	// Ref is the "$ref" of the reference object holding the node, empty for
+	// inline nodes.
+	Ref string
+	// Value is the object, see the NodeKind constants for its type. It is nil
+	// for a reference that was not resolved.
+	Value any
+	// Extensions is the extensions map of the object (nil when it has none).
+	// The openapi3 model keeps OpenAPI 3.1 keywords (e.g. $defs) in it as
+	// well, including the webhooks of the document and the pathItems of the
+	// components, which are also walked as KindPathItem nodes.
+	Extensions map[string]any
+	// RefExtensions is the extensions map of the reference object holding the
+	// node (e.g. the *openapi3.SchemaRef of a schema), nil for other nodes.
+	RefExtensions map[string]any
+}
+
+// RefPolicy tells a Walker what to do with the target of a reference.
+type RefPolicy int
+
+const (
+	// SkipRefs visits the reference nodes without walking the children of
+	// their target, which are walked where the target is defined (e.g. under
+	// /components). Only the objects defined in the document are walked.
+	SkipRefs RefPolicy = iota
+	// FollowRefs walks the children of the target of a reference where it is
+	// referenced, which also reaches the targets defined outside of the
+	// document (e.g. external references).
+	FollowRefs
+)
+
+// SkipChildren is returned by Walker.Enter to skip the children of a node.
+// Leave is still called for the node.
+var SkipChildren = errors.New("skip children")
+
+// Walker walks an OpenAPI document and calls Enter before and Leave after
+// walking the children of every object. Both callbacks are optional, and an
+// error other than SkipChildren stops the walk and is returned.
+//
+// Enter is called for every occurrence of an object, but its children are
+// walked once per Walker: a schema referenced from several places (or from
+// itself, e.g. Node.children: [Node]) is only walked at its first
+// occurrence. A Walker can walk several documents or parts of them (see
+// WalkValue) and remembers the objects walked by all of them.
+//
+// The OpenAPI 3.1 webhooks and components pathItems are walked as KindPathItem
+// nodes at "/webhooks/<name>" and "/components/pathItems/<name>". The openapi3
+// model keeps them as raw values, so they are decoded into copies that are
+// written back when the callbacks change them. A webhook referencing one of
+// the components pathItems is never written back: its target is changed where
+// it is defined.
+type Walker struct {
+	Enter func(node *Node) error
+	Leave func(node *Node) error
+	Refs  RefPolicy
+
+	walked map[any]struct{}
+}
+
+// Walk walks the document, starting with its KindDocument node.
+func (w *Walker) Walk(doc *openapi3.T) error {
+	if doc == nil {
+		return ErrOpenAPINotFound
+	}
+	return w.visit(&Node{Kind: KindDocument, Value: doc, Extensions: doc.Extensions}, func(pointer string) error {
+		if err := w.walkInfo(pointer+"/info", doc.Info); err != nil {
+			return err
+		}
+		if err := w.walkServers(pointer+"/servers", doc.Servers); err != nil {
+			return err
+		}
+		if err := w.walkPaths(pointer+"/paths", doc.Paths); err != nil {
+			return err
+		}
+		if err := w.walkRawPathItems(pointer+"/webhooks", webhooks(doc), doc.Components); err != nil {
+			return err
+		}
+		if err := w.walkComponents(pointer+"/components", doc.Components); err != nil {
+			return err
+		}
+		for i, tag := range doc.Tags {
+			if err := w.walkTag(pointer+"/tags/"+strconv.Itoa(i), tag); err != nil {
+				return err
+			}
+		}
+		return w.walkExternalDocs(pointer+"/externalDocs", doc.ExternalDocs)
+	})
+}
+
+// WalkValue walks a part of a document located at the JSON pointer. value is
+// one of the types listed by the NodeKind constants or a reference object
+// holding one (e.g. *openapi3.SchemaRef).
+func (w *Walker) WalkValue(pointer string, value any) error {
+	switch value := value.(type) {
+	case *openapi3.T:
+		return w.Walk(value)
+	case *openapi3.Info:
+		return w.walkInfo(pointer, value)
+	case *openapi3.Server:
+		return w.walkServer(pointer, value)
+	case *openapi3.Tag:
+		return w.walkTag(pointer, value)
+	case *openapi3.ExternalDocs:
+		return w.walkExternalDocs(pointer, value)
+	case *openapi3.Components:
+		return w.walkComponents(pointer, value)
+	case *openapi3.Paths:
+		return w.walkPaths(pointer, value)
+	case *openapi3.PathItem:
+		return w.walkPathItem(pointer, value)
+	case *openapi3.Operation:
+		return w.walkOperation(pointer, value)
+	case *openapi3.ParameterRef:
+		return w.walkParameter(pointer, value)
+	case *openapi3.Parameter:
+		return w.walkParameter(pointer, &openapi3.ParameterRef{Value: value})
+	case *openapi3.RequestBodyRef:
+		return w.walkRequestBody(pointer, value)
+	case *openapi3.RequestBody:
+		return w.walkRequestBody(pointer, &openapi3.RequestBodyRef{Value: value})
+	case *openapi3.Responses:
+		return w.walkResponses(pointer, value)
+	case *openapi3.ResponseRef:
+		return w.walkResponse(pointer, value)
+	case *openapi3.Response:
+		return w.walkResponse(pointer, &openapi3.ResponseRef{Value: value})
+	case *openapi3.HeaderRef:
+		return w.walkHeader(pointer, value)
+	case *openapi3.Header:
+		return w.walkHeader(pointer, &openapi3.HeaderRef{Value: value})
+	case *openapi3.MediaType:
+		return w.walkMediaType(pointer, value)
+	case *openapi3.ExampleRef:
+		return w.walkExample(pointer, value)
+	case *openapi3.Example:
+		return w.walkExample(pointer, &openapi3.ExampleRef{Value: value})
+	case *openapi3.LinkRef:
+		return w.walkLink(pointer, value)
+	case *openapi3.Link:
+		return w.walkLink(pointer, &openapi3.LinkRef{Value: value})
+	case *openapi3.CallbackRef:
+		return w.walkCallback(pointer, value)
+	case *openapi3.Callback:
+		return w.walkCallback(pointer, &openapi3.CallbackRef{Value: value})
+	case *openapi3.SchemaRef:
+		return w.walkSchema(pointer, value)
+	case *openapi3.Schema:
+		return w.walkSchema(pointer, &openapi3.SchemaRef{Value: value})
+	case *openapi3.SecuritySchemeRef:
+		return w.walkSecurityScheme(pointer, value)
+	case *openapi3.SecurityScheme:
+		return w.walkSecurityScheme(pointer, &openapi3.SecuritySchemeRef{Value: value})
+	}
+	return fmt.Errorf("cannot walk %T", value)
+}
+
+// visit calls Enter, walks the children of the node unless they were already
+// walked (or the node is a reference and refs are skipped), and calls Leave.
+func (w *Walker) visit(node *Node, children func(pointer string) error) error {
+	if isNilValue(node.Value) {
+		node.Value = nil // e.g. an unresolved reference
+	}
+	if w.Enter != nil {
+		err := w.Enter(node)
+		if errors.Is(err, SkipChildren) {
+			children = nil
+		} else if err != nil {
+			return err
+		}
+	}
+	if node.Ref != "" && w.Refs == SkipRefs {
+		children = nil
+	}
+	if children != nil && node.Value != nil {
+		if w.walked == nil {
+			w.walked = make(map[any]struct{})
+		}
+		if _, ok := w.walked[node.Value]; !ok {
+			w.walked[node.Value] = struct{}{}
+			if err := children(node.Pointer); err != nil {
+				return err
+			}
+		}
+	}
+	if w.Leave != nil {
+		return w.Leave(node)
+	}
+	return nil
+}
+
+// isNilValue reports whether v is nil or a nil pointer of one of the node
+// value types.
+func isNilValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case *openapi3.T:
+		return v == nil
+	case *openapi3.Info:
+		return v == nil
+	case *openapi3.Contact:
+		return v == nil
+	case *openapi3.License:
+		return v == nil
+	case *openapi3.Server:
+		return v == nil
+	case *openapi3.ServerVariable:
+		return v == nil
+	case *openapi3.Tag:
+		return v == nil
+	case *openapi3.ExternalDocs:
+		return v == nil
+	case *openapi3.Components:
+		return v == nil
+	case *openapi3.Paths:
+		return v == nil
+	case *openapi3.PathItem:
+		return v == nil
+	case *openapi3.Operation:
+		return v == nil
+	case *openapi3.Parameter:
+		return v == nil
+	case *openapi3.RequestBody:
+		return v == nil
+	case *openapi3.Responses:
+		return v == nil
+	case *openapi3.Response:
+		return v == nil
+	case *openapi3.Header:
+		return v == nil
+	case *openapi3.MediaType:
+		return v == nil
+	case *openapi3.Encoding:
+		return v == nil
+	case *openapi3.Example:
+		return v == nil
+	case *openapi3.Link:
+		return v == nil
+	case *openapi3.Callback:
+		return v == nil
+	case *openapi3.Schema:
+		return v == nil
+	case *openapi3.Discriminator:
+		return v == nil
+	case *openapi3.XML:
+		return v == nil
+	case *openapi3.SecurityScheme:
+		return v == nil
+	case *openapi3.OAuthFlows:
+		return v == nil
+	case *openapi3.OAuthFlow:
+		return v == nil
+	}
+	return false
+}
+
+// JSONPointerToken escapes a key for use as a JSON pointer reference token
+// ("~" becomes "~0" and "/" becomes "~1").
+func JSONPointerToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of m in sorted order, so walks are
+// deterministic.
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func (w *Walker) walkInfo(pointer string, info *openapi3.Info) error {
+	if info == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindInfo, Pointer: pointer, Value: info, Extensions: info.Extensions}, func(pointer string) error {
+		if contact := info.Contact; contact != nil {
+			if err := w.visit(&Node{Kind: KindContact, Pointer: pointer + "/contact", Value: contact, Extensions: contact.Extensions}, nil); err != nil {
+				return err
+			}
+		}
+		if license := info.License; license != nil {
+			return w.visit(&Node{Kind: KindLicense, Pointer: pointer + "/license", Value: license, Extensions: license.Extensions}, nil)
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkServers(pointer string, servers openapi3.Servers) error {
+	for i, server := range servers {
+		if err := w.walkServer(pointer+"/"+strconv.Itoa(i), server); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) walkServer(pointer string, server *openapi3.Server) error {
+	if server == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindServer, Pointer: pointer, Value: server, Extensions: server.Extensions}, func(pointer string) error {
+		for _, name := range sortedKeys(server.Variables) {
+			variable := server.Variables[name]
+			if variable == nil {
+				continue
+			}
+			node := &Node{Kind: KindServerVariable, Pointer: pointer + "/variables/" + JSONPointerToken(name), Value: variable, Extensions: variable.Extensions}
+			if err := w.visit(node, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkTag(pointer string, tag *openapi3.Tag) error {
+	if tag == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindTag, Pointer: pointer, Value: tag, Extensions: tag.Extensions}, func(pointer string) error {
+		return w.walkExternalDocs(pointer+"/externalDocs", tag.ExternalDocs)
+	})
+}
+
+func (w *Walker) walkExternalDocs(pointer string, docs *openapi3.ExternalDocs) error {
+	if docs == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindExternalDocs, Pointer: pointer, Value: docs, Extensions: docs.Extensions}, nil)
+}
+
+func (w *Walker) walkComponents(pointer string, components *openapi3.Components) error {
+	if components == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindComponents, Pointer: pointer, Value: components, Extensions: components.Extensions}, func(pointer string) error {
+		for _, name := range sortedKeys(components.Schemas) {
+			if err := w.walkSchema(pointer+"/schemas/"+JSONPointerToken(name), components.Schemas[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Parameters) {
+			if err := w.walkParameter(pointer+"/parameters/"+JSONPointerToken(name), components.Parameters[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Headers) {
+			if err := w.walkHeader(pointer+"/headers/"+JSONPointerToken(name), components.Headers[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.RequestBodies) {
+			if err := w.walkRequestBody(pointer+"/requestBodies/"+JSONPointerToken(name), components.RequestBodies[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Responses) {
+			if err := w.walkResponse(pointer+"/responses/"+JSONPointerToken(name), components.Responses[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.SecuritySchemes) {
+			if err := w.walkSecurityScheme(pointer+"/securitySchemes/"+JSONPointerToken(name), components.SecuritySchemes[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Examples) {
+			if err := w.walkExample(pointer+"/examples/"+JSONPointerToken(name), components.Examples[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Links) {
+			if err := w.walkLink(pointer+"/links/"+JSONPointerToken(name), components.Links[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(components.Callbacks) {
+			if err := w.walkCallback(pointer+"/callbacks/"+JSONPointerToken(name), components.Callbacks[name]); err != nil {
+				return err
+			}
+		}
+		pathItems, _ := components.Extensions[rawPathItemsKey(KindComponents)].(map[string]any)
+		return w.walkRawPathItems(pointer+"/pathItems", pathItems, components)
+	})
+}
+
+func (w *Walker) walkPaths(pointer string, paths *openapi3.Paths) error {
+	if paths == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindPaths, Pointer: pointer, Value: paths, Extensions: paths.Extensions}, func(pointer string) error {
+		items := paths.Map()
+		for _, path := range sortedKeys(items) {
+			if err := w.walkPathItem(pointer+"/"+JSONPointerToken(path), items[path]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkPathItem(pointer string, pathItem *openapi3.PathItem) error {
+	if pathItem == nil {
+		return nil
+	}
+	node := &Node{Kind: KindPathItem, Pointer: pointer, Ref: pathItem.Ref, Value: pathItem, Extensions: pathItem.Extensions}
+	return w.visit(node, func(pointer string) error {
+		if err := w.walkServers(pointer+"/servers", pathItem.Servers); err != nil {
+			return err
+		}
+		if err := w.walkParameters(pointer+"/parameters", pathItem.Parameters); err != nil {
+			return err
+		}
+		operations := pathItem.Operations()
+		for _, method := range sortedKeys(operations) {
+			if err := w.walkOperation(pointer+"/"+strings.ToLower(method), operations[method]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// walkRawPathItems walks the raw OpenAPI 3.1 path items and writes back the
+// ones the callbacks changed. A reference to the components pathItems is
+// walked with its target, as a typed path item reference would be.
+func (w *Walker) walkRawPathItems(pointer string, items map[string]any, components *openapi3.Components) error {
+	for _, name := range sortedKeys(items) {
+		itemPointer := pointer + "/" + JSONPointerToken(name)
+		pathItem := decodePathItem(items[name])
+		if pathItem == nil {
+			continue
+		}
+		if pathItem.Ref != "" {
+			if target := decodePathItem(resolveRawPathItem(components, items[name])); target != nil && target.Ref == "" {
+				target.Ref = pathItem.Ref
+				pathItem = target
+			}
+			if err := w.walkPathItem(itemPointer, pathItem); err != nil {
+				return err
+			}
+			continue
+		}
+		item, err := updateRawPathItem(items[name], func(pathItem *openapi3.PathItem) error {
+			return w.walkPathItem(itemPointer, pathItem)
+		})
+		if err != nil {
+			return err
+		}
+		items[name] = item
+	}
+	return nil
+}
+
+func (w *Walker) walkOperation(pointer string, operation *openapi3.Operation) error {
+	if operation == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindOperation, Pointer: pointer, Value: operation, Extensions: operation.Extensions}, func(pointer string) error {
+		if err := w.walkParameters(pointer+"/parameters", operation.Parameters); err != nil {
+			return err
+		}
+		if err := w.walkRequestBody(pointer+"/requestBody", operation.RequestBody); err != nil {
+			return err
+		}
+		if err := w.walkResponses(pointer+"/responses", operation.Responses); err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(operation.Callbacks) {
+			if err := w.walkCallback(pointer+"/callbacks/"+JSONPointerToken(name), operation.Callbacks[name]); err != nil {
+				return err
+			}
+		}
+		if operation.Servers != nil {
+			if err := w.walkServers(pointer+"/servers", *operation.Servers); err != nil {
+				return err
+			}
+		}
+		return w.walkExternalDocs(pointer+"/externalDocs", operation.ExternalDocs)
+	})
+}
+
+func (w *Walker) walkParameters(pointer string, params openapi3.Parameters) error {
+	for i, param := range params {
+		if err := w.walkParameter(pointer+"/"+strconv.Itoa(i), param); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) walkParameter(pointer string, param *openapi3.ParameterRef) error {
+	if param == nil {
+		return nil
+	}
+	node := &Node{Kind: KindParameter, Pointer: pointer, Ref: param.Ref, Value: param.Value, RefExtensions: param.Extensions}
+	if param.Value != nil {
+		node.Extensions = param.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		return w.walkParameterFields(pointer, param.Value)
+	})
+}
+
+// walkParameterFields walks the children shared by parameters and headers.
+func (w *Walker) walkParameterFields(pointer string, param *openapi3.Parameter) error {
+	if err := w.walkSchema(pointer+"/schema", param.Schema); err != nil {
+		return err
+	}
+	if err := w.walkContent(pointer+"/content", param.Content); err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(param.Examples) {
+		if err := w.walkExample(pointer+"/examples/"+JSONPointerToken(name), param.Examples[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) walkRequestBody(pointer string, requestBody *openapi3.RequestBodyRef) error {
+	if requestBody == nil {
+		return nil
+	}
+	node := &Node{Kind: KindRequestBody, Pointer: pointer, Ref: requestBody.Ref, Value: requestBody.Value, RefExtensions: requestBody.Extensions}
+	if requestBody.Value != nil {
+		node.Extensions = requestBody.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		return w.walkContent(pointer+"/content", requestBody.Value.Content)
+	})
+}
+
+func (w *Walker) walkResponses(pointer string, responses *openapi3.Responses) error {
+	if responses == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindResponses, Pointer: pointer, Value: responses, Extensions: responses.Extensions}, func(pointer string) error {
+		items := responses.Map()
+		for _, status := range sortedKeys(items) {
+			if err := w.walkResponse(pointer+"/"+JSONPointerToken(status), items[status]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkResponse(pointer string, response *openapi3.ResponseRef) error {
+	if response == nil {
+		return nil
+	}
+	node := &Node{Kind: KindResponse, Pointer: pointer, Ref: response.Ref, Value: response.Value, RefExtensions: response.Extensions}
+	if response.Value != nil {
+		node.Extensions = response.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		value := response.Value
+		for _, name := range sortedKeys(value.Headers) {
+			if err := w.walkHeader(pointer+"/headers/"+JSONPointerToken(name), value.Headers[name]); err != nil {
+				return err
+			}
+		}
+		if err := w.walkContent(pointer+"/content", value.Content); err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(value.Links) {
+			if err := w.walkLink(pointer+"/links/"+JSONPointerToken(name), value.Links[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkHeader(pointer string, header *openapi3.HeaderRef) error {
+	if header == nil {
+		return nil
+	}
+	node := &Node{Kind: KindHeader, Pointer: pointer, Ref: header.Ref, Value: header.Value, RefExtensions: header.Extensions}
+	if header.Value != nil {
+		node.Extensions = header.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		return w.walkParameterFields(pointer, &header.Value.Parameter)
+	})
+}
+
+func (w *Walker) walkContent(pointer string, content openapi3.Content) error {
+	for _, name := range sortedKeys(content) {
+		if err := w.walkMediaType(pointer+"/"+JSONPointerToken(name), content[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Walker) walkMediaType(pointer string, mediaType *openapi3.MediaType) error {
+	if mediaType == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindMediaType, Pointer: pointer, Value: mediaType, Extensions: mediaType.Extensions}, func(pointer string) error {
+		if err := w.walkSchema(pointer+"/schema", mediaType.Schema); err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(mediaType.Examples) {
+			if err := w.walkExample(pointer+"/examples/"+JSONPointerToken(name), mediaType.Examples[name]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(mediaType.Encoding) {
+			if err := w.walkEncoding(pointer+"/encoding/"+JSONPointerToken(name), mediaType.Encoding[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkEncoding(pointer string, encoding *openapi3.Encoding) error {
+	if encoding == nil {
+		return nil
+	}
+	return w.visit(&Node{Kind: KindEncoding, Pointer: pointer, Value: encoding, Extensions: encoding.Extensions}, func(pointer string) error {
+		for _, name := range sortedKeys(encoding.Headers) {
+			if err := w.walkHeader(pointer+"/headers/"+JSONPointerToken(name), encoding.Headers[name]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkExample(pointer string, example *openapi3.ExampleRef) error {
+	if example == nil {
+		return nil
+	}
+	node := &Node{Kind: KindExample, Pointer: pointer, Ref: example.Ref, Value: example.Value, RefExtensions: example.Extensions}
+	if example.Value != nil {
+		node.Extensions = example.Value.Extensions
+	}
+	// The value of an example is literal data
+	return w.visit(node, nil)
+}
+
+func (w *Walker) walkLink(pointer string, link *openapi3.LinkRef) error {
+	if link == nil {
+		return nil
+	}
+	node := &Node{Kind: KindLink, Pointer: pointer, Ref: link.Ref, Value: link.Value, RefExtensions: link.Extensions}
+	if link.Value != nil {
+		node.Extensions = link.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		return w.walkServer(pointer+"/server", link.Value.Server)
+	})
+}
+
+func (w *Walker) walkCallback(pointer string, callback *openapi3.CallbackRef) error {
+	if callback == nil {
+		return nil
+	}
+	node := &Node{Kind: KindCallback, Pointer: pointer, Ref: callback.Ref, Value: callback.Value, RefExtensions: callback.Extensions}
+	if callback.Value != nil {
+		node.Extensions = callback.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		items := callback.Value.Map()
+		for _, expression := range sortedKeys(items) {
+			if err := w.walkPathItem(pointer+"/"+JSONPointerToken(expression), items[expression]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (w *Walker) walkSchema(pointer string, schema *openapi3.SchemaRef) error {
+	if schema == nil {
+		return nil
+	}
+	node := &Node{Kind: KindSchema, Pointer: pointer, Ref: schema.Ref, Value: schema.Value, RefExtensions: schema.Extensions}
+	if schema.Value != nil {
+		node.Extensions = schema.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		value := schema.Value
+		if err := w.walkExternalDocs(pointer+"/externalDocs", value.ExternalDocs); err != nil {
+			return err
+		}
+		if discriminator := value.Discriminator; discriminator != nil {
+			node := &Node{Kind: KindDiscriminator, Pointer: pointer + "/discriminator", Value: discriminator, Extensions: discriminator.Extensions}
+			if err := w.visit(node, nil); err != nil {
+				return err
+			}
+		}
+		if xml := value.XML; xml != nil {
+			if err := w.visit(&Node{Kind: KindXML, Pointer: pointer + "/xml", Value: xml, Extensions: xml.Extensions}, nil); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(value.Properties) {
+			if err := w.walkSchema(pointer+"/properties/"+JSONPointerToken(name), value.Properties[name]); err != nil {
+				return err
+			}
+		}
+		if err := w.walkSchema(pointer+"/items", value.Items); err != nil {
+			return err
+		}
+		for _, composition := range []struct {
+			keyword string
+			schemas openapi3.SchemaRefs
+		}{{"allOf", value.AllOf}, {"oneOf", value.OneOf}, {"anyOf", value.AnyOf}} {
+			for i, subSchema := range composition.schemas {
+				if err := w.walkSchema(pointer+"/"+composition.keyword+"/"+strconv.Itoa(i), subSchema); err != nil {
+					return err
+				}
+			}
+		}
+		if err := w.walkSchema(pointer+"/not", value.Not); err != nil {
+			return err
+		}
+		return w.walkSchema(pointer+"/additionalProperties", value.AdditionalProperties.Schema)
+	})
+}
+
+func (w *Walker) walkSecurityScheme(pointer string, securityScheme *openapi3.SecuritySchemeRef) error {
+	if securityScheme == nil {
+		return nil
+	}
+	node := &Node{Kind: KindSecurityScheme, Pointer: pointer, Ref: securityScheme.Ref, Value: securityScheme.Value, RefExtensions: securityScheme.Extensions}
+	if securityScheme.Value != nil {
+		node.Extensions = securityScheme.Value.Extensions
+	}
+	return w.visit(node, func(pointer string) error {
+		flows := securityScheme.Value.Flows
+		if flows == nil {
+			return nil
+		}
+		return w.visit(&Node{Kind: KindOAuthFlows, Pointer: pointer + "/flows", Value: flows, Extensions: flows.Extensions}, func(pointer string) error {
+			for _, flow := range []struct {
+				name string
+				flow *openapi3.OAuthFlow
+			}{
+				{"implicit", flows.Implicit},
+				{"password", flows.Password},
+				{"clientCredentials", flows.ClientCredentials},
+				{"authorizationCode", flows.AuthorizationCode},
+			} {
+				if flow.flow == nil {
+					continue
+				}
+				node := &Node{Kind: KindOAuthFlow, Pointer: pointer + "/" + flow.name, Value: flow.flow, Extensions: flow.flow.Extensions}
+				if err := w.visit(node, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
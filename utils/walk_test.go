@@ -0,0 +1,195 @@
+package utils_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadWalkAPIDoc(t *testing.T) *openapi3.T {
+	t.Helper()
+	doc, err := openapi3.NewLoader().LoadFromData(walkFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// walkPointers returns the pointers of the nodes entered by a walk, suffixed
+// with the reference of the node when it has one.
+func walkPointers(t *testing.T, doc *openapi3.T, refs utils.RefPolicy) []string {
+	t.Helper()
+	var pointers []string
+	walker := &utils.Walker{
+		Refs: refs,
+		Enter: func(node *utils.Node) error {
+			pointer := string(node.Kind) + " " + node.Pointer
+			if node.Ref != "" {
+				pointer += " -> " + node.Ref
+			}
+			pointers = append(pointers, pointer)
+			return nil
+		},
+	}
+	if err := walker.Walk(doc); err != nil {
+		t.Fatal(err)
+	}
+	return pointers
+}
+
+func TestWalkerSkipRefs(t *testing.T) {
+	pointers := walkPointers(t, loadWalkAPIDoc(t), utils.SkipRefs)
+
+	assert.Equal(t, []string{
+		"document ",
+		"info /info",
+		"paths /paths",
+		"pathItem /paths/~1users~1{id}",
+		"parameter /paths/~1users~1{id}/parameters/0 -> #/components/parameters/UserID",
+		"operation /paths/~1users~1{id}/get",
+		"responses /paths/~1users~1{id}/get/responses",
+		"response /paths/~1users~1{id}/get/responses/200",
+		"mediaType /paths/~1users~1{id}/get/responses/200/content/application~1json",
+		"schema /paths/~1users~1{id}/get/responses/200/content/application~1json/schema -> #/components/schemas/User",
+		"components /components",
+		"schema /components/schemas/User",
+		"schema /components/schemas/User/properties/friends",
+		"schema /components/schemas/User/properties/friends/items -> #/components/schemas/User",
+		"schema /components/schemas/User/properties/name",
+		"parameter /components/parameters/UserID",
+		"schema /components/parameters/UserID/schema",
+	}, pointers)
+}
+
+func TestWalkerFollowRefs(t *testing.T) {
+	pointers := walkPointers(t, loadWalkAPIDoc(t), utils.FollowRefs)
+
+	// The targets are walked where they are first referenced, and only once
+	assert.Contains(t, pointers, "schema /paths/~1users~1{id}/parameters/0/schema")
+	assert.Contains(t, pointers, "schema /paths/~1users~1{id}/get/responses/200/content/application~1json/schema/properties/name")
+	assert.NotContains(t, pointers, "schema /components/schemas/User/properties/name")
+	assert.NotContains(t, pointers, "schema /components/parameters/UserID/schema")
+	// The recursive reference is entered, but not walked again
+	assert.Contains(t, pointers, "schema /paths/~1users~1{id}/get/responses/200/content/application~1json/schema/properties/friends/items -> #/components/schemas/User")
+	assert.Contains(t, pointers, "schema /components/schemas/User")
+	for _, pointer := range pointers {
+		assert.NotContains(t, pointer, "/items/properties")
+	}
+}
+
+func TestWalkerEnterLeave(t *testing.T) {
+	doc := loadWalkAPIDoc(t)
+	var events []string
+	walker := &utils.Walker{
+		Enter: func(node *utils.Node) error {
+			if node.Kind == utils.KindComponents {
+				return utils.SkipChildren
+			}
+			events = append(events, "enter "+node.Pointer)
+			return nil
+		},
+		Leave: func(node *utils.Node) error {
+			events = append(events, "leave "+node.Pointer)
+			return nil
+		},
+	}
+	if err := walker.Walk(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "enter ", events[0])
+	assert.Equal(t, "leave ", events[len(events)-1])
+	enterOperation := slices.Index(events, "enter /paths/~1users~1{id}/get")
+	leaveResponse := slices.Index(events, "leave /paths/~1users~1{id}/get/responses/200")
+	leaveOperation := slices.Index(events, "leave /paths/~1users~1{id}/get")
+	assert.True(t, enterOperation >= 0 && enterOperation < leaveResponse && leaveResponse < leaveOperation)
+	// Leave is called for a node whose children are skipped
+	assert.Contains(t, events, "leave /components")
+	for _, event := range events {
+		assert.False(t, strings.HasPrefix(event, "enter /components/"), event)
+	}
+}
+
+func TestWalkerWebhooks(t *testing.T) {
+	doc, err := utils.LoadDocument(nil, splitWebhooksFile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pointers := walkPointers(t, doc, utils.SkipRefs)
+	assert.Subset(t, pointers, []string{
+		"pathItem /webhooks/invoice.paid",
+		"operation /webhooks/invoice.paid/post",
+		"requestBody /webhooks/invoice.paid/post/requestBody -> #/components/requestBodies/InvoiceEvent",
+		"pathItem /webhooks/invoice.voided -> #/components/pathItems/InvoiceVoided",
+		"parameter /webhooks/user.created/parameters/0 -> #/components/parameters/Signature",
+		"pathItem /components/pathItems/InvoiceVoided",
+		"operation /components/pathItems/InvoiceVoided/put",
+	})
+	assert.NotContains(t, pointers, "operation /webhooks/invoice.voided/post")
+	assert.Contains(t, walkPointers(t, doc, utils.FollowRefs), "operation /webhooks/invoice.voided/post")
+
+	// The changes made to the decoded path items are written back
+	walker := &utils.Walker{
+		Refs: utils.FollowRefs,
+		Enter: func(node *utils.Node) error {
+			if operation, ok := node.Value.(*openapi3.Operation); ok {
+				operation.Summary = "walked " + node.Pointer
+			}
+			return nil
+		},
+	}
+	if err := walker.Walk(doc); err != nil {
+		t.Fatal(err)
+	}
+	webhooks := doc.Extensions["webhooks"].(map[string]any)
+	paid := webhooks["invoice.paid"].(map[string]any)
+	assert.Equal(t, "walked /webhooks/invoice.paid/post", paid["post"].(map[string]any)["summary"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/pathItems/InvoiceVoided"}, webhooks["invoice.voided"], "A reference is not written back")
+	voided := doc.Components.Extensions["pathItems"].(map[string]any)["InvoiceVoided"].(map[string]any)
+	assert.Equal(t, "walked /components/pathItems/InvoiceVoided/post", voided["post"].(map[string]any)["summary"])
+}
+
+func TestWalkerError(t *testing.T) {
+	doc := loadWalkAPIDoc(t)
+	errStop := errors.New("stop")
+	entered := 0
+	walker := &utils.Walker{
+		Enter: func(node *utils.Node) error {
+			entered++
+			if node.Kind == utils.KindOperation {
+				return errStop
+			}
+			return nil
+		},
+	}
+	assert.ErrorIs(t, walker.Walk(doc), errStop)
+	assert.Equal(t, 6, entered)
+}
+
+func TestWalkerWalkValue(t *testing.T) {
+	doc := loadWalkAPIDoc(t)
+	var pointers []string
+	walker := &utils.Walker{
+		Enter: func(node *utils.Node) error {
+			pointers = append(pointers, node.Pointer)
+			return nil
+		},
+	}
+	if err := walker.WalkValue("/components/parameters/UserID", doc.Components.Parameters["UserID"]); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/components/parameters/UserID", "/components/parameters/UserID/schema"}, pointers)
+
+	assert.Error(t, walker.WalkValue("/info/title", doc.Info.Title))
+}
+
+func TestJSONPointerToken(t *testing.T) {
+	assert.Equal(t, "~1users~1{id}", utils.JSONPointerToken("/users/{id}"))
+	assert.Equal(t, "a~0b~1c", utils.JSONPointerToken("a~b/c"))
+}
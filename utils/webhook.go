@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WebhookTargetPrefix marks a split target as an OpenAPI 3.1 webhook name
+// pattern (e.g. "webhook:invoice.paid" or "webhook:invoice.*") instead of a
+// path pattern.
+const WebhookTargetPrefix = "webhook:"
+
+// webhooks returns the raw OpenAPI 3.1 webhooks of the document, which the
+// openapi3 model keeps in the document extensions.
+func webhooks(doc *openapi3.T) map[string]any {
+	items, _ := doc.Extensions["webhooks"].(map[string]any)
+	return items
+}
+
+// webhookPathItem decodes a raw webhook into a path item, resolving a
+// "#/components/pathItems" reference. It returns nil when the webhook is not
+// a valid path item.
+func webhookPathItem(doc *openapi3.T, raw any) *openapi3.PathItem {
+	return decodePathItem(resolveRawPathItem(doc.Components, raw))
+}
+
+// decodePathItem decodes a raw OpenAPI 3.1 path item, leaving its references
+// unresolved. It returns nil when raw is not a valid path item.
+func decodePathItem(raw any) *openapi3.PathItem {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	pathItem := &openapi3.PathItem{}
+	if err := pathItem.UnmarshalJSON(data); err != nil {
+		return nil
+	}
+	return pathItem
+}
+
+// updateRawPathItem calls update with the decoded raw OpenAPI 3.1 path item
+// and returns the raw value of the result, or raw itself when update changed
+// nothing or raw is not a valid path item.
+func updateRawPathItem(raw any, update func(pathItem *openapi3.PathItem) error) (any, error) {
+	pathItem := decodePathItem(raw)
+	if pathItem == nil {
+		return raw, nil
+	}
+	before, err := pathItem.MarshalJSON()
+	if err != nil {
+		return raw, nil
+	}
+	if err := update(pathItem); err != nil {
+		return raw, err
+	}
+	after, err := pathItem.MarshalJSON()
+	if err != nil || bytes.Equal(before, after) {
+		return raw, nil
+	}
+	var item map[string]any
+	if err := json.Unmarshal(after, &item); err != nil {
+		return raw, nil
+	}
+	return item, nil
+}
+
+// rawPathItemsKey returns the key of the OpenAPI 3.1 path items that the
+// openapi3 model keeps in the extensions of a node of the kind: the webhooks
+// of the document and the pathItems of the components. A Walker walks them as
+// KindPathItem nodes.
+func rawPathItemsKey(kind NodeKind) string {
+	switch kind {
+	case KindDocument:
+		return "webhooks"
+	case KindComponents:
+		return "pathItems"
+	}
+	return ""
+}
+
+// resolveRawPathItem returns the path item a raw "#/components/pathItems"
+// reference points to, or raw itself when it is not such a reference.
+func resolveRawPathItem(components *openapi3.Components, raw any) any {
+	item, ok := raw.(map[string]any)
+	if !ok || components == nil {
+		return raw
+	}
+	ref, _ := item["$ref"].(string)
+	if typ, key := ExtractReferenceName(ref); typ != "pathItems" {
+		return raw
+	} else if pathItems, _ := components.Extensions["pathItems"].(map[string]any); pathItems[key] != nil {
+		return pathItems[key]
+	}
+	return raw
+}
+
+// webhookOperations returns the operations of the document webhooks, keyed by
+// webhook name.
+func webhookOperations(doc *openapi3.T) map[string]map[string]*openapi3.Operation {
+	operations := make(map[string]map[string]*openapi3.Operation)
+	for name, raw := range webhooks(doc) {
+		if pathItem := webhookPathItem(doc, raw); pathItem != nil {
+			operations[name] = pathItem.Operations()
+		}
+	}
+	return operations
+}
+
+// collectWebhooks copies the selected webhooks of the source document into the
+// split document and collects the components they reference, as collectPaths
+// does for paths. selected maps WebhookTargetPrefix followed by the webhook
+// name to the upper-cased methods to keep, all methods are kept when empty.
+func (c *componentCollector) collectWebhooks(selected map[string]map[string]bool) {
+	for name, raw := range webhooks(c.doc) {
+		methods, ok := selected[WebhookTargetPrefix+name]
+		if !ok {
+			continue
+		}
+		pathItem := webhookPathItem(c.doc, raw)
+		if pathItem == nil {
+			continue
+		}
+		splitItem := raw
+		if len(methods) > 0 {
+			splitItem = pruneRawPathItem(resolveRawPathItem(c.doc.Components, raw), methods)
+		} else if item, ok := raw.(map[string]any); ok && item["$ref"] != nil {
+			// Collect the path item itself when it is an OpenAPI 3.1 "#/components/pathItems" reference
+			if ref, ok := item["$ref"].(string); ok {
+				c.collectRef(ref)
+			}
+		}
+		pointer := "/webhooks/" + JSONPointerToken(name)
+		for method, operation := range pathItem.Operations() {
+			if operation == nil || (len(methods) > 0 && !methods[method]) {
+				continue
+			}
+			c.walk(pointer+"/"+strings.ToLower(method), operation)
+		}
+		for i, param := range pathItem.Parameters {
+			c.walk(pointer+"/parameters/"+strconv.Itoa(i), param)
+		}
+
+		if c.splitDoc.Extensions == nil {
+			c.splitDoc.Extensions = make(map[string]any)
+		}
+		items, _ := c.splitDoc.Extensions["webhooks"].(map[string]any)
+		if items == nil {
+			items = make(map[string]any)
+			c.splitDoc.Extensions["webhooks"] = items
+		}
+		items[name] = splitItem
+	}
+}
+
+// pruneRawPathItem returns a copy of a raw path item with only the operations
+// of the given upper-cased methods. A reference would bring back every
+// operation, so raw must already be resolved.
+func pruneRawPathItem(raw any, methods map[string]bool) any {
+	item, ok := raw.(map[string]any)
+	if !ok {
+		return raw
+	}
+	pruned := make(map[string]any, len(item))
+	for key, value := range item {
+		method := strings.ToUpper(key)
+		if isHTTPMethod(method) && !methods[method] {
+			continue
+		}
+		pruned[key] = value
+	}
+	return pruned
+}
+
+func isHTTPMethod(method string) bool {
+	switch method {
+	case "CONNECT", "DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT", "TRACE":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package utils_test
+
+import (
+	"github.com/0x726f6f6b6965/openapi-fmt/utils"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+)
+
+// webhookItems returns the raw webhooks of a split document.
+func webhookItems(doc *openapi3.T) map[string]any {
+	items, _ := doc.Extensions["webhooks"].(map[string]any)
+	return items
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"webhook:invoice.paid": {}})
+	assert.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), output.Paths.Map())
+	webhooks := webhookItems(output)
+	assert.Len(suite.T(), webhooks, 1)
+	assert.Contains(suite.T(), webhooks, "invoice.paid")
+
+	assert.Contains(suite.T(), output.Components.RequestBodies, "InvoiceEvent")
+	assert.Contains(suite.T(), output.Components.Schemas, "InvoiceEvent")
+	assert.Contains(suite.T(), output.Components.Schemas, "Invoice", "Missing Invoice (via InvoiceEvent)")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.Schemas, "VoidReason")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "hmac", "Missing the webhook security scheme")
+	assert.Contains(suite.T(), output.Components.SecuritySchemes, "apiKey", "Missing the global security scheme")
+	assert.Equal(suite.T(), []string{"invoices"}, tagNames(output.Tags))
+	assert.Nil(suite.T(), output.Components.Extensions["pathItems"])
+
+	// The source document keeps all its webhooks
+	assert.Len(suite.T(), webhookItems(doc), 3)
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_WebhookPatternAndPath() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{
+		"webhook:invoice.*": {},
+		"/invoices":         {},
+	})
+	assert.NoError(suite.T(), err)
+
+	assert.NotNil(suite.T(), output.Paths.Value("/invoices"))
+	webhooks := webhookItems(output)
+	assert.Len(suite.T(), webhooks, 2)
+	assert.Equal(suite.T(), map[string]any{"$ref": "#/components/pathItems/InvoiceVoided"}, webhooks["invoice.voided"])
+
+	pathItems, _ := output.Components.Extensions["pathItems"].(map[string]any)
+	assert.Contains(suite.T(), pathItems, "InvoiceVoided")
+	assert.Contains(suite.T(), output.Components.Schemas, "VoidReason", "Missing VoidReason (via the InvoiceVoided path item)")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_PathsKeepWebhooks() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"/invoices": {}})
+	assert.NoError(suite.T(), err)
+
+	// Without webhook targets every webhook is kept, with its components
+	assert.NotNil(suite.T(), output.Paths.Value("/invoices"))
+	assert.Len(suite.T(), webhookItems(output), 3)
+	assert.Contains(suite.T(), output.Components.Schemas, "User")
+	assert.Contains(suite.T(), output.Components.Schemas, "VoidReason")
+	assert.Contains(suite.T(), output.Components.Parameters, "Signature")
+
+	// The kept webhooks do not count as a match of the path targets
+	_, err = utils.SplitByPath(doc, map[string][]string{"/orders": {}})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIPathNotFound)
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_WebhookMethods() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByPath(doc, map[string][]string{"webhook:invoice.voided": {"put"}})
+	assert.NoError(suite.T(), err)
+
+	// A reference would bring back every operation, so the path item is inlined
+	webhook, _ := webhookItems(output)["invoice.voided"].(map[string]any)
+	assert.NotNil(suite.T(), webhook)
+	assert.Nil(suite.T(), webhook["$ref"])
+	assert.NotNil(suite.T(), webhook["put"])
+	assert.Nil(suite.T(), webhook["post"])
+
+	assert.Contains(suite.T(), output.Components.Schemas, "VoidReason")
+	assert.NotContains(suite.T(), output.Components.Schemas, "InvoiceEvent")
+	assert.Nil(suite.T(), output.Components.Extensions["pathItems"])
+}
+
+func (suite *UtilsTestSuite) TestSplitByPath_WebhookNotFound() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	unmatched, err := utils.UnmatchedPathPatterns(doc, map[string][]string{
+		"webhook:order.*": {},
+		"invoice.paid":    {}, // not a path
+		"/invoices":       {},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []string{"invoice.paid", "webhook:order.*"}, unmatched)
+
+	_, err = utils.SplitByPath(doc, map[string][]string{"webhook:order.*": {}})
+	assert.ErrorIs(suite.T(), err, utils.ErrOpenAPIPathNotFound)
+	assert.ErrorContains(suite.T(), err, "webhook:order.*")
+}
+
+func (suite *UtilsTestSuite) TestSplitByTag_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByTag(doc, []string{"users"})
+	assert.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), output.Paths.Map())
+	webhooks := webhookItems(output)
+	assert.Len(suite.T(), webhooks, 1)
+	assert.Contains(suite.T(), webhooks, "user.created")
+	assert.Contains(suite.T(), output.Components.Schemas, "User")
+	assert.Contains(suite.T(), output.Components.Parameters, "Signature", "Missing the webhook-level parameter")
+	assert.Contains(suite.T(), output.Components.Schemas, "Signature")
+	assert.Equal(suite.T(), []string{"users"}, tagNames(output.Tags))
+}
+
+func (suite *UtilsTestSuite) TestSplitByOperationID_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.SplitByOperationID(doc, []string{"listInvoices", "invoiceVoidReason"})
+	assert.NoError(suite.T(), err)
+
+	assert.NotNil(suite.T(), output.Paths.Value("/invoices"))
+	webhooks := webhookItems(output)
+	assert.Len(suite.T(), webhooks, 1)
+	webhook, _ := webhooks["invoice.voided"].(map[string]any)
+	assert.NotNil(suite.T(), webhook["put"])
+	assert.Nil(suite.T(), webhook["post"])
+}
+
+func (suite *UtilsTestSuite) TestExcludePaths_Webhook() {
+	doc := suite.loadDoc(splitWebhooksFile)
+	output, err := utils.ExcludePaths(doc, map[string][]string{"webhook:user.*": {}})
+	assert.NoError(suite.T(), err)
+
+	assert.NotNil(suite.T(), output.Paths.Value("/invoices"))
+	webhooks := webhookItems(output)
+	assert.Len(suite.T(), webhooks, 2)
+	assert.NotContains(suite.T(), webhooks, "user.created")
+	assert.NotContains(suite.T(), output.Components.Schemas, "User")
+	assert.NotContains(suite.T(), output.Components.Parameters, "Signature")
+	assert.Contains(suite.T(), output.Components.Schemas, "VoidReason")
+	assert.Equal(suite.T(), []string{"invoices"}, tagNames(output.Tags))
+}